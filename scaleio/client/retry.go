@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RetryPolicy defines how requests to the ScaleIO gateway are retried when
+// they fail
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts of a request, including
+	// the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled on each retry
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts
+	MaxDelay time.Duration
+	// RetryableStatusCodes are the HTTP status codes worth a retry
+	RetryableStatusCodes []int
+	// RetryConnectionErrors retries the requests which got no response at
+	// all, e.g. on connection reset
+	RetryConnectionErrors bool
+}
+
+// DefaultRetryPolicy retries gateway errors and connection errors twice
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	RetryableStatusCodes: []int{
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+	RetryConnectionErrors: true,
+}
+
+// jitter is the random source of the backoff delays. It is seeded so that
+// plugin processes do not draw the same delays, and guarded by jitterMutex as
+// a rand.Rand is not safe for concurrent use.
+var (
+	jitter      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	jitterMutex sync.Mutex
+)
+
+// SetRetryPolicy replaces the retry policy of the client. It must not be
+// called while the client is in use.
+func (c *SIOClient) SetRetryPolicy(p RetryPolicy) {
+	c.retryPolicy = p
+}
+
+// backoff returns the delay before the given retry, starting at 1. Half of
+// the delay is random so that clients do not retry in lockstep.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	jitterMutex.Lock()
+	defer jitterMutex.Unlock()
+	return delay/2 + time.Duration(jitter.Int63n(int64(delay/2)+1))
+}
+
+func (p RetryPolicy) retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return p.RetryConnectionErrors
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// do sends the request, retrying it according to the retry policy. body is
+// the content of the request body, if any, as it has to be sent again on
+// every attempt.
+func (c *SIOClient) do(req *http.Request, body []byte) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		if body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+		}
+		resp, err := c.client.Do(req)
+		if attempt >= c.retryPolicy.MaxAttempts || !c.retryPolicy.retryable(resp, err) {
+			return resp, err
+		}
+		// a cancelled request must not be retried
+		if req.Context().Err() != nil {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := c.sleep(req.Context(), c.retryPolicy.backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleepContext waits for d, or until ctx is done in which case it returns the
+// context error
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,192 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleio
+
+import (
+	"sort"
+	"strings"
+)
+
+// bwc holds the three components of a ScaleIO Bwc (bandwidth counter)
+// structure
+type bwc struct {
+	numSeconds      float64
+	totalWeightInKb float64
+	numOccured      float64
+}
+
+// derivation computes a derived value from a Bwc structure
+type derivation struct {
+	name    string
+	compute func(b bwc) float64
+}
+
+// bwcDerivations are the values derived from every *Bwc structure. All of
+// them are 0 when there was no activity to divide by.
+var bwcDerivations = []derivation{
+	{"iops", func(b bwc) float64 { return ratio(b.numOccured, b.numSeconds) }},
+	{"kbps", func(b bwc) float64 { return ratio(b.totalWeightInKb, b.numSeconds) }},
+	{"avgIoSizeKb", func(b bwc) float64 { return ratio(b.totalWeightInKb, b.numOccured) }},
+}
+
+// latencyDerivations are the values derived from every *Latency structure,
+// which is Bwc-style but accumulates microseconds in totalWeightInKb
+var latencyDerivations = []derivation{
+	{"avgLatencyInMicrosec", func(b bwc) float64 { return ratio(b.totalWeightInKb, b.numOccured) }},
+}
+
+// derivationsFor returns the derivations available for a statistics key
+func derivationsFor(key string) []derivation {
+	switch {
+	case strings.HasSuffix(key, "Bwc"):
+		return bwcDerivations
+	case strings.HasSuffix(key, "Latency"):
+		return latencyDerivations
+	}
+	return nil
+}
+
+// findDerivation returns the derivation named name for a statistics key
+func findDerivation(key string, name string) (derivation, bool) {
+	for _, d := range derivationsFor(key) {
+		if d.name == name {
+			return d, true
+		}
+	}
+	return derivation{}, false
+}
+
+// withDerivedKeys returns keys followed by the derived keys of every
+// structure found in keys
+func withDerivedKeys(keys [][]string) [][]string {
+	all := append([][]string{}, keys...)
+	seen := map[string]bool{}
+	for _, k := range keys {
+		if len(k) != 2 || seen[k[0]] {
+			continue
+		}
+		seen[k[0]] = true
+		for _, d := range derivationsFor(k[0]) {
+			all = append(all, []string{k[0], d.name})
+		}
+	}
+	return all
+}
+
+// toBwc reads a Bwc structure as returned by the gateway
+func toBwc(data map[string]interface{}) bwc {
+	b := bwc{}
+	b.numSeconds, _ = data["numSeconds"].(float64)
+	b.totalWeightInKb, _ = data["totalWeightInKb"].(float64)
+	b.numOccured, _ = data["numOccured"].(float64)
+	return b
+}
+
+// ratio divides a by b, returning 0 rather than dividing by zero
+func ratio(a float64, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+// statsDerivation computes a derived value from the statistics of an instance
+type statsDerivation struct {
+	name    string
+	compute func(stats map[string]interface{}) float64
+}
+
+// derivedGroup is a set of values derived from several statistics of an
+// instance, exposed under /intel/scaleio/<family>/<ID>/<group>/<name>
+type derivedGroup struct {
+	// inputs are the statistics the values are computed from
+	inputs []string
+	values []statsDerivation
+}
+
+// derivedGroups lists the derived groups of each ScaleIO object type by name
+var derivedGroups = map[string]map[string]derivedGroup{
+	storagePoolType: map[string]derivedGroup{
+		"capacity": poolCapacityGroup,
+	},
+}
+
+// poolCapacityGroup holds the capacity ratios of a storage pool
+var poolCapacityGroup = derivedGroup{
+	inputs: []string{
+		"maxCapacityInKb",
+		"capacityInUseInKb",
+		"unusedCapacityInKb",
+		"thinCapacityAllocatedInKm",
+		"thinCapacityInUseInKb",
+		"thickCapacityInUseInKb",
+		"snapCapacityInUseOccupiedInKb",
+	},
+	values: []statsDerivation{
+		{"usedPercent", func(s map[string]interface{}) float64 {
+			return 100 * ratio(floatField(s, "capacityInUseInKb"), floatField(s, "maxCapacityInKb"))
+		}},
+		{"freePercent", func(s map[string]interface{}) float64 {
+			return 100 * ratio(floatField(s, "unusedCapacityInKb"), floatField(s, "maxCapacityInKb"))
+		}},
+		{"thinProvisioningRatio", func(s map[string]interface{}) float64 {
+			return ratio(floatField(s, "thinCapacityAllocatedInKm"), floatField(s, "thinCapacityInUseInKb"))
+		}},
+		{"overprovisioningRatio", func(s map[string]interface{}) float64 {
+			provisioned := floatField(s, "thinCapacityAllocatedInKm") + floatField(s, "thickCapacityInUseInKb")
+			return ratio(provisioned, floatField(s, "maxCapacityInKb"))
+		}},
+		{"snapshotOverheadPercent", func(s map[string]interface{}) float64 {
+			return 100 * ratio(floatField(s, "snapCapacityInUseOccupiedInKb"), floatField(s, "capacityInUseInKb"))
+		}},
+	},
+}
+
+// derivedGroupKeys returns the keys of every derived group of an object type
+func derivedGroupKeys(objType string) [][]string {
+	names := []string{}
+	for name := range derivedGroups[objType] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	keys := [][]string{}
+	for _, name := range names {
+		for _, v := range derivedGroups[objType][name].values {
+			keys = append(keys, []string{name, v.name})
+		}
+	}
+	return keys
+}
+
+// compute returns the value named name, or nil if the group has none
+func (g derivedGroup) compute(name string, stats map[string]interface{}) interface{} {
+	for _, v := range g.values {
+		if v.name == name {
+			return v.compute(stats)
+		}
+	}
+	return nil
+}
+
+// floatField returns the numeric statistic stored under key, or 0
+func floatField(stats map[string]interface{}, key string) float64 {
+	v, _ := stats[key].(float64)
+	return v
+}
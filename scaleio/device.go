@@ -0,0 +1,21 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// deviceMetrics collects the Statistics and state of every Device, tagged
+// with its owning SDS and storage pool
+func (s *ScaleIO) deviceMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	tagger := func(device map[string]interface{}) map[string]string {
+		return map[string]string{
+			"deviceName":    stringField(device, "name"),
+			"sdsId":         stringField(device, "sdsId"),
+			"storagePoolId": stringField(device, "storagePoolId"),
+		}
+	}
+	return statisticsMetrics(ctx, client, deviceType, nss, tagger)
+}
@@ -0,0 +1,38 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+const (
+	// faultSetSdsKey is the derived gauge holding the number of SDSs that
+	// are members of a fault set
+	faultSetSdsKey = "numOfSds"
+)
+
+// faultSetMetrics collects the Statistics of every FaultSet along with the
+// number of SDSs in each of them
+func (s *ScaleIO) faultSetMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	tagger := func(fs map[string]interface{}) map[string]string {
+		return map[string]string{
+			"faultSetName":       stringField(fs, "name"),
+			"protectionDomainId": stringField(fs, "protectionDomainId"),
+		}
+	}
+	mts, err := statisticsMetrics(ctx, client, faultSetType, nss, tagger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fault set membership is only known from the SDS side
+	err = countMembers(ctx, client, mts, faultSetSdsKey, sdsType, func(sds map[string]interface{}) string {
+		return stringField(sds, "faultSetId")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mts, nil
+}
@@ -0,0 +1,179 @@
+package scaleio
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+const (
+	forecastGroup         = "forecast"
+	daysUntilFullKey      = "daysUntilFull"
+	defaultForecastWindow = "24h"
+	// neverFull is reported when the used capacity of a pool is not growing
+	neverFull = -1
+)
+
+// capacitySample is the used capacity of a storage pool at a point in time
+type capacitySample struct {
+	timestamp time.Time
+	inUseInKb float64
+}
+
+// forecastKey identifies the samples of a pool of a gateway kept for a
+// forecast window. Tasks with different windows keep their own samples, so
+// that a short window does not drop the samples a longer one needs.
+type forecastKey struct {
+	gateway string
+	poolID  string
+	window  time.Duration
+}
+
+// isForecastInput tells whether a pool namespace was only added to the pool
+// query to feed the forecast
+func isForecastInput(ns plugin.Namespace) bool {
+	return ns[instanceIDIdx+1].Name == forecastGroup
+}
+
+// forecastMetrics records the used capacity of the pools found in inputs,
+// the pool statistics queried for the forecastInputs of nss, and forecasts
+// the number of days until they are full using a linear regression over the
+// samples of the window. Nothing is reported for a pool until it has at
+// least two samples.
+func (s *ScaleIO) forecastMetrics(nss []plugin.Namespace, inputs []plugin.Metric, gateway string, window time.Duration) ([]plugin.Metric, error) {
+	for _, ns := range nss {
+		if ns[instanceIDIdx+2].Value != daysUntilFullKey {
+			return nil, fmt.Errorf("Invalid metric namespace given: %v", ns)
+		}
+	}
+	// Pools selected by several namespaces are only sampled once
+	type poolCapacity struct {
+		sample      capacitySample
+		maxCapacity float64
+		tags        map[string]string
+	}
+	pools := map[string]*poolCapacity{}
+	ids := []string{}
+	for _, m := range inputs {
+		id := m.Namespace[instanceIDIdx].Value
+		value, ok := m.Data.(float64)
+		if !ok {
+			continue
+		}
+		pool, seen := pools[id]
+		if !seen {
+			pool = &poolCapacity{sample: capacitySample{timestamp: m.Timestamp}, tags: m.Tags}
+			pools[id] = pool
+			ids = append(ids, id)
+		}
+		switch m.Namespace[instanceIDIdx+1].Value {
+		case "capacityInUseInKb":
+			pool.sample.inUseInKb = value
+		case "maxCapacityInKb":
+			pool.maxCapacity = value
+		}
+	}
+
+	results := []plugin.Metric{}
+	for _, id := range ids {
+		pool := pools[id]
+		days, ok := s.daysUntilFull(forecastKey{gateway, id, window}, pool.sample, pool.maxCapacity)
+		if !ok {
+			continue
+		}
+		for _, ns := range nss {
+			if !matchesInstance(ns[instanceIDIdx].Value, map[string]interface{}{"id": id, "name": pool.tags["storagePoolName"]}) {
+				continue
+			}
+			dyn := make([]plugin.NamespaceElement, len(ns))
+			copy(dyn, ns)
+			dyn[instanceIDIdx].Value = id
+			results = append(results, plugin.Metric{
+				Namespace: dyn,
+				Timestamp: pool.sample.timestamp,
+				Data:      days,
+				Tags:      pool.tags,
+			})
+			break
+		}
+	}
+	return results, nil
+}
+
+// daysUntilFull adds the sample to the window of the pool identified by key
+// and returns the forecast number of days until the pool is full
+func (s *ScaleIO) daysUntilFull(key forecastKey, sample capacitySample, maxCapacity float64) (float64, bool) {
+	window := key.window
+	s.mutex.Lock()
+	samples := append(s.capacitySamples[key], sample)
+	// Drop the samples which left the window
+	start := 0
+	for start < len(samples) && sample.timestamp.Sub(samples[start].timestamp) > window {
+		start++
+	}
+	samples = samples[start:]
+	s.capacitySamples[key] = samples
+	// Forget the pools which have not been sampled within the window, e.g.
+	// because they were removed
+	for k, other := range s.capacitySamples {
+		if k.gateway == key.gateway && k.window == window && sample.timestamp.Sub(other[len(other)-1].timestamp) > window {
+			delete(s.capacitySamples, k)
+		}
+	}
+	s.mutex.Unlock()
+
+	if len(samples) < 2 {
+		return 0, false
+	}
+	slope := capacitySlope(samples)
+	if slope <= 0 {
+		return neverFull, true
+	}
+	days := (maxCapacity - sample.inUseInKb) / slope / (24 * time.Hour).Seconds()
+	if days < 0 {
+		return 0, true
+	}
+	return days, true
+}
+
+// capacitySlope returns the growth of the used capacity in KB per second,
+// from the least squares fit of the samples
+func capacitySlope(samples []capacitySample) float64 {
+	n := float64(len(samples))
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		x := sample.timestamp.Sub(samples[0].timestamp).Seconds()
+		y := sample.inUseInKb
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return ratio(n*sumXY-sumX*sumY, n*sumXX-sumX*sumX)
+}
+
+// forecastInput returns the namespace of the pool statistic key, for the
+// pools selected by the forecast namespace ns. It is marked so that
+// isForecastInput tells it apart from a namespace asked by the task.
+func forecastInput(ns plugin.Namespace, key string) plugin.Namespace {
+	input := make([]plugin.NamespaceElement, instanceIDIdx+1)
+	copy(input, ns)
+	return append(input, plugin.NamespaceElement{Name: forecastGroup, Value: key})
+}
+
+// getForecastWindow returns the forecast window set in the config
+func getForecastWindow(cfg plugin.Config) (time.Duration, error) {
+	window, err := cfg.GetString("forecastWindow")
+	if err != nil {
+		window = defaultForecastWindow
+	}
+	d, err := time.ParseDuration(window)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid forecastWindow %q: %v", window, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("Invalid forecastWindow %q: must be positive", window)
+	}
+	return d, nil
+}
@@ -1,5 +1,227 @@
 package scaleio
 
+// nonStatisticsKeys lists, per ScaleIO object type, the metric keys which are
+// not part of the Statistics and so must not be queried from the gateway.
+// They are either taken from the instance listing or derived by the collector.
+var nonStatisticsKeys = map[string]map[string]bool{
+	deviceType: map[string]bool{
+		"deviceState": true,
+		"errorState":  true,
+	},
+	faultSetType: map[string]bool{
+		faultSetSdsKey: true,
+	},
+	vtreeType: map[string]bool{
+		vtreeSnapshotsKey: true,
+	},
+}
+
+// counterKeys are the monotonic counters converted to rates in rate mode
+var counterKeys = map[string]bool{
+	"BackgroundScanCompareCount":             true,
+	"BackgroundScannedInMB":                  true,
+	"BackgroundScanFixedReadErrorCount":      true,
+	"BackgroundScanFixedCompareErrorCount":   true,
+	"fixedReadErrorCount":                    true,
+	"rfcacheReadsReceived":                   true,
+	"rfcacheWritesReceived":                  true,
+	"rfcacheReadsFromCache":                  true,
+	"rfacheReadHit":                          true,
+	"rfcacheReadMiss":                        true,
+	"rfcacheWriteMiss":                       true,
+	"rfcacheIosSkipped":                      true,
+	"rfcacheIoErrors":                        true,
+	"rfcacheReadsSkipped":                    true,
+	"rfcacheReadsSkippedAlignedSizeTooLarge": true,
+	"rfcacheReadsSkippedHeavyLoad":           true,
+	"rfcacheReadsSkippedInternalError":       true,
+	"rfcacheReadsSkippedLockIos":             true,
+	"rfcacheReadsSkippedLowResources":        true,
+	"rfcacheReadsSkippedMaxIoSize":           true,
+	"rfcacheReadsSkippedStuckIo":             true,
+	"rfcacheSkippedUnlinedWrite":             true,
+	"rfcacheSourceDeviceReads":               true,
+	"rfcacheSourceDeviceWrites":              true,
+	"rfcacheWritesSkippedCacheMiss":          true,
+	"rfcacheWritesSkippedHeavyLoad":          true,
+	"rfcacheWritesSkippedInternalError":      true,
+	"rfcacheWritesSkippedLowResources":       true,
+	"rfcacheWritesSkippedMaxIoSize":          true,
+	"rfcacheWritesSkippedStuckIo":            true,
+	"rfcacheFdReadsReceived":                 true,
+	"rfcacheFdWritesReceived":                true,
+	"rfcacheFdReadHit":                       true,
+	"rfcacheFdReadMiss":                      true,
+	"rfcacheFdWriteHit":                      true,
+	"rfcacheFdWriteMiss":                     true,
+	"rfcacheFdReadsSkipped":                  true,
+	"rfcacheFdWritesSkipped":                 true,
+	"rfcacheFdIoErrors":                      true,
+	"rfcacheFdCacheOverloaded":               true,
+	"rmcacheEntryEvictionCount":              true,
+	"rmcacheBigBlockEvictionCount":           true,
+	"rmcacheNoEvictionCount":                 true,
+	"rmcacheSkipCountLargeIo":                true,
+	"rmcacheSkipCountUnaligned4kbIo":         true,
+	"rmcacheSkipCountCacheAllBusy":           true,
+}
+
+// metricUnits gives the unit of the capacity statistics, whatever their
+// object type. thinCapacityAllocatedInKm is reported in KB despite its name.
+var metricUnits = map[string]capacityUnit{
+	"activeBckRebuildCapacityInKb":             kbUnit,
+	"activeFwdRebuildCapacityInKb":             kbUnit,
+	"activeMovingCapacityInKb":                 kbUnit,
+	"activeNormRebuildCapacityInKb":            kbUnit,
+	"activeRebalanceCapacityInKb":              kbUnit,
+	"atRestCapacityInKb":                       kbUnit,
+	"BackgroundScannedInMB":                    mbUnit,
+	"baseNetCapacityInUseInKb":                 kbUnit,
+	"bckRebuildCapacityInKb":                   kbUnit,
+	"capacityAvailableForVolumeAllocationInKb": kbUnit,
+	"capacityInUseInKb":                        kbUnit,
+	"capacityLimitInKb":                        kbUnit,
+	"degradedFailedCapacityInKb":               kbUnit,
+	"degradedFailedVacInKb":                    kbUnit,
+	"degradedHealthyCapacityInKb":              kbUnit,
+	"degradedHealthyVacInKb":                   kbUnit,
+	"failedCapacityInKb":                       kbUnit,
+	"failedVacInKb":                            kbUnit,
+	"fwdRebuildCapacityInKb":                   kbUnit,
+	"inMaintenanceCapacityInKb":                kbUnit,
+	"inMaintenanceVacInKb":                     kbUnit,
+	"inUseVacInKb":                             kbUnit,
+	"maxCapacityInKb":                          kbUnit,
+	"movingCapacityInKb":                       kbUnit,
+	"netCapacityInUseInKb":                     kbUnit,
+	"normRebuildCapacityInKb":                  kbUnit,
+	"pendingBckRebuildCapacityInKb":            kbUnit,
+	"pendingFwdRebuildCapacityInKb":            kbUnit,
+	"pendingMovingCapacityInKb":                kbUnit,
+	"pendingNormRebuildCapacityInKb":           kbUnit,
+	"pendingRebalanceCapacityInKb":             kbUnit,
+	"primaryVacInKb":                           kbUnit,
+	"protectedCapacityInKb":                    kbUnit,
+	"protectedVacInKb":                         kbUnit,
+	"rebalanceCapacityInKb":                    kbUnit,
+	"rmcacheSizeInKb":                          kbUnit,
+	"rmcacheSizeInUseInKb":                     kbUnit,
+	"rmPendingAllocatedInKb":                   kbUnit,
+	"secondaryVacInKb":                         kbUnit,
+	"semiProtectedCapacityInKb":                kbUnit,
+	"semiProtectedVacInKb":                     kbUnit,
+	"snapCapacityInUseInKb":                    kbUnit,
+	"snapCapacityInUseOccupiedInKb":            kbUnit,
+	"snapNetCapacityInUseInKb":                 kbUnit,
+	"spareCapacityInKb":                        kbUnit,
+	"thickCapacityInUseInKb":                   kbUnit,
+	"thinCapacityAllocatedInKm":                kbUnit,
+	"thinCapacityInUseInKb":                    kbUnit,
+	"trimmedCapacityInKb":                      kbUnit,
+	"unreachableUnusedCapacityInKb":            kbUnit,
+	"unusedCapacityInKb":                       kbUnit,
+}
+
+var systemMetricKeys = [][]string{
+	[]string{"numOfProtectionDomains"},
+	[]string{"numOfStoragePools"},
+	[]string{"numOfFaultSets"},
+	[]string{"numOfSds"},
+	[]string{"numOfSdc"},
+	[]string{"numOfDevices"},
+	[]string{"numOfRfcacheDevices"},
+	[]string{"numOfVolumes"},
+	[]string{"numOfVtrees"},
+	[]string{"numOfSnapshots"},
+	[]string{"numOfThickBaseVolumes"},
+	[]string{"numOfThinBaseVolumes"},
+	[]string{"numOfMappedToAllVolumes"},
+	[]string{"numOfUnmappedVolumes"},
+	[]string{"numOfVolumesInDeletion"},
+	[]string{"numOfScsiInitiators"},
+	[]string{"maxCapacityInKb"},
+	[]string{"capacityInUseInKb"},
+	[]string{"unusedCapacityInKb"},
+	[]string{"capacityLimitInKb"},
+	[]string{"capacityAvailableForVolumeAllocationInKb"},
+	[]string{"thinCapacityInUseInKb"},
+	[]string{"thickCapacityInUseInKb"},
+	[]string{"thinCapacityAllocatedInKm"},
+	[]string{"snapCapacityInUseInKb"},
+	[]string{"snapCapacityInUseOccupiedInKb"},
+	[]string{"spareCapacityInKb"},
+	[]string{"protectedCapacityInKb"},
+	[]string{"failedCapacityInKb"},
+	[]string{"degradedHealthyCapacityInKb"},
+	[]string{"degradedFailedCapacityInKb"},
+	[]string{"inMaintenanceCapacityInKb"},
+	[]string{"semiProtectedCapacityInKb"},
+	[]string{"unreachableUnusedCapacityInKb"},
+	[]string{"rebalanceCapacityInKb"},
+	[]string{"pendingRebalanceCapacityInKb"},
+	[]string{"activeRebalanceCapacityInKb"},
+	[]string{"fwdRebuildCapacityInKb"},
+	[]string{"pendingFwdRebuildCapacityInKb"},
+	[]string{"activeFwdRebuildCapacityInKb"},
+	[]string{"bckRebuildCapacityInKb"},
+	[]string{"pendingBckRebuildCapacityInKb"},
+	[]string{"activeBckRebuildCapacityInKb"},
+	[]string{"normRebuildCapacityInKb"},
+	[]string{"pendingNormRebuildCapacityInKb"},
+	[]string{"activeNormRebuildCapacityInKb"},
+	[]string{"movingCapacityInKb"},
+	[]string{"activeMovingCapacityInKb"},
+	[]string{"pendingMovingCapacityInKb"},
+	[]string{"primaryReadBwc", "numSeconds"},
+	[]string{"primaryReadBwc", "totalWeightInKb"},
+	[]string{"primaryReadBwc", "numOccured"},
+	[]string{"primaryWriteBwc", "numSeconds"},
+	[]string{"primaryWriteBwc", "totalWeightInKb"},
+	[]string{"primaryWriteBwc", "numOccured"},
+	[]string{"secondaryReadBwc", "numSeconds"},
+	[]string{"secondaryReadBwc", "totalWeightInKb"},
+	[]string{"secondaryReadBwc", "numOccured"},
+	[]string{"secondaryWriteBwc", "numSeconds"},
+	[]string{"secondaryWriteBwc", "totalWeightInKb"},
+	[]string{"secondaryWriteBwc", "numOccured"},
+	[]string{"totalReadBwc", "numSeconds"},
+	[]string{"totalReadBwc", "totalWeightInKb"},
+	[]string{"totalReadBwc", "numOccured"},
+	[]string{"totalWriteBwc", "numSeconds"},
+	[]string{"totalWriteBwc", "totalWeightInKb"},
+	[]string{"totalWriteBwc", "numOccured"},
+	[]string{"userDataReadBwc", "numSeconds"},
+	[]string{"userDataReadBwc", "totalWeightInKb"},
+	[]string{"userDataReadBwc", "numOccured"},
+	[]string{"userDataWriteBwc", "numSeconds"},
+	[]string{"userDataWriteBwc", "totalWeightInKb"},
+	[]string{"userDataWriteBwc", "numOccured"},
+	[]string{"fwdRebuildReadBwc", "numSeconds"},
+	[]string{"fwdRebuildReadBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildReadBwc", "numOccured"},
+	[]string{"fwdRebuildWriteBwc", "numSeconds"},
+	[]string{"fwdRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildWriteBwc", "numOccured"},
+	[]string{"bckRebuildReadBwc", "numSeconds"},
+	[]string{"bckRebuildReadBwc", "totalWeightInKb"},
+	[]string{"bckRebuildReadBwc", "numOccured"},
+	[]string{"bckRebuildWriteBwc", "numSeconds"},
+	[]string{"bckRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"bckRebuildWriteBwc", "numOccured"},
+	[]string{"normRebuildReadBwc", "numSeconds"},
+	[]string{"normRebuildReadBwc", "totalWeightInKb"},
+	[]string{"normRebuildReadBwc", "numOccured"},
+	[]string{"normRebuildWriteBwc", "numSeconds"},
+	[]string{"normRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"normRebuildWriteBwc", "numOccured"},
+	[]string{"rebalanceReadBwc", "numSeconds"},
+	[]string{"rebalanceReadBwc", "totalWeightInKb"},
+	[]string{"rebalanceReadBwc", "numOccured"},
+	[]string{"rebalanceWriteBwc", "numSeconds"},
+	[]string{"rebalanceWriteBwc", "totalWeightInKb"},
+	[]string{"rebalanceWriteBwc", "numOccured"},
+}
+
 var storagePoolMetricKeys = [][]string{
 	[]string{"pendingMovingOutBckRebuildJobs"},
 	[]string{"secondaryVacInKb"},
@@ -157,4 +379,528 @@ var storagePoolMetricKeys = [][]string{
 	[]string{"rebalanceWriteBwc", "totalWeightInKb"},
 	[]string{"rebalanceWriteBwc", "numOccured"},
 	[]string{"primaryVacInKb"},
+	[]string{"userDataSdcReadLatency", "numSeconds"},
+	[]string{"userDataSdcReadLatency", "totalWeightInKb"},
+	[]string{"userDataSdcReadLatency", "numOccured"},
+	[]string{"userDataSdcWriteLatency", "numSeconds"},
+	[]string{"userDataSdcWriteLatency", "totalWeightInKb"},
+	[]string{"userDataSdcWriteLatency", "numOccured"},
+	[]string{"userDataSdcTrimLatency", "numSeconds"},
+	[]string{"userDataSdcTrimLatency", "totalWeightInKb"},
+	[]string{"userDataSdcTrimLatency", "numOccured"},
+}
+
+var protectionDomainMetricKeys = [][]string{
+	[]string{"numOfSds"},
+	[]string{"numOfStoragePools"},
+	[]string{"numOfFaultSets"},
+	[]string{"numOfDevices"},
+	[]string{"numOfRfcacheDevices"},
+	[]string{"numOfVolumes"},
+	[]string{"numOfVtrees"},
+	[]string{"numOfSnapshots"},
+	[]string{"numOfThickBaseVolumes"},
+	[]string{"numOfThinBaseVolumes"},
+	[]string{"numOfMappedToAllVolumes"},
+	[]string{"numOfUnmappedVolumes"},
+	[]string{"numOfVolumesInDeletion"},
+	[]string{"maxCapacityInKb"},
+	[]string{"capacityInUseInKb"},
+	[]string{"unusedCapacityInKb"},
+	[]string{"capacityLimitInKb"},
+	[]string{"capacityAvailableForVolumeAllocationInKb"},
+	[]string{"thinCapacityInUseInKb"},
+	[]string{"thickCapacityInUseInKb"},
+	[]string{"thinCapacityAllocatedInKm"},
+	[]string{"snapCapacityInUseInKb"},
+	[]string{"snapCapacityInUseOccupiedInKb"},
+	[]string{"spareCapacityInKb"},
+	[]string{"protectedCapacityInKb"},
+	[]string{"failedCapacityInKb"},
+	[]string{"degradedHealthyCapacityInKb"},
+	[]string{"degradedFailedCapacityInKb"},
+	[]string{"inMaintenanceCapacityInKb"},
+	[]string{"semiProtectedCapacityInKb"},
+	[]string{"unreachableUnusedCapacityInKb"},
+	[]string{"atRestCapacityInKb"},
+	[]string{"rmPendingAllocatedInKb"},
+	[]string{"rebalanceCapacityInKb"},
+	[]string{"pendingRebalanceCapacityInKb"},
+	[]string{"activeRebalanceCapacityInKb"},
+	[]string{"fwdRebuildCapacityInKb"},
+	[]string{"pendingFwdRebuildCapacityInKb"},
+	[]string{"activeFwdRebuildCapacityInKb"},
+	[]string{"bckRebuildCapacityInKb"},
+	[]string{"pendingBckRebuildCapacityInKb"},
+	[]string{"activeBckRebuildCapacityInKb"},
+	[]string{"normRebuildCapacityInKb"},
+	[]string{"pendingNormRebuildCapacityInKb"},
+	[]string{"activeNormRebuildCapacityInKb"},
+	[]string{"movingCapacityInKb"},
+	[]string{"activeMovingCapacityInKb"},
+	[]string{"pendingMovingCapacityInKb"},
+	[]string{"primaryVacInKb"},
+	[]string{"secondaryVacInKb"},
+	[]string{"protectedVacInKb"},
+	[]string{"inUseVacInKb"},
+	[]string{"failedVacInKb"},
+	[]string{"degradedHealthyVacInKb"},
+	[]string{"degradedFailedVacInKb"},
+	[]string{"semiProtectedVacInKb"},
+	[]string{"inMaintenanceVacInKb"},
+	[]string{"fixedReadErrorCount"},
+	[]string{"BackgroundScanCompareCount"},
+	[]string{"BackgroundScannedInMB"},
+	[]string{"primaryReadBwc", "numSeconds"},
+	[]string{"primaryReadBwc", "totalWeightInKb"},
+	[]string{"primaryReadBwc", "numOccured"},
+	[]string{"primaryWriteBwc", "numSeconds"},
+	[]string{"primaryWriteBwc", "totalWeightInKb"},
+	[]string{"primaryWriteBwc", "numOccured"},
+	[]string{"secondaryReadBwc", "numSeconds"},
+	[]string{"secondaryReadBwc", "totalWeightInKb"},
+	[]string{"secondaryReadBwc", "numOccured"},
+	[]string{"secondaryWriteBwc", "numSeconds"},
+	[]string{"secondaryWriteBwc", "totalWeightInKb"},
+	[]string{"secondaryWriteBwc", "numOccured"},
+	[]string{"totalReadBwc", "numSeconds"},
+	[]string{"totalReadBwc", "totalWeightInKb"},
+	[]string{"totalReadBwc", "numOccured"},
+	[]string{"totalWriteBwc", "numSeconds"},
+	[]string{"totalWriteBwc", "totalWeightInKb"},
+	[]string{"totalWriteBwc", "numOccured"},
+	[]string{"primaryReadFromDevBwc", "numSeconds"},
+	[]string{"primaryReadFromDevBwc", "totalWeightInKb"},
+	[]string{"primaryReadFromDevBwc", "numOccured"},
+	[]string{"secondaryReadFromDevBwc", "numSeconds"},
+	[]string{"secondaryReadFromDevBwc", "totalWeightInKb"},
+	[]string{"secondaryReadFromDevBwc", "numOccured"},
+	[]string{"primaryReadFromRmcacheBwc", "numSeconds"},
+	[]string{"primaryReadFromRmcacheBwc", "totalWeightInKb"},
+	[]string{"primaryReadFromRmcacheBwc", "numOccured"},
+	[]string{"secondaryReadFromRmcacheBwc", "numSeconds"},
+	[]string{"secondaryReadFromRmcacheBwc", "totalWeightInKb"},
+	[]string{"secondaryReadFromRmcacheBwc", "numOccured"},
+	[]string{"fwdRebuildReadBwc", "numSeconds"},
+	[]string{"fwdRebuildReadBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildReadBwc", "numOccured"},
+	[]string{"fwdRebuildWriteBwc", "numSeconds"},
+	[]string{"fwdRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildWriteBwc", "numOccured"},
+	[]string{"bckRebuildReadBwc", "numSeconds"},
+	[]string{"bckRebuildReadBwc", "totalWeightInKb"},
+	[]string{"bckRebuildReadBwc", "numOccured"},
+	[]string{"bckRebuildWriteBwc", "numSeconds"},
+	[]string{"bckRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"bckRebuildWriteBwc", "numOccured"},
+	[]string{"normRebuildReadBwc", "numSeconds"},
+	[]string{"normRebuildReadBwc", "totalWeightInKb"},
+	[]string{"normRebuildReadBwc", "numOccured"},
+	[]string{"normRebuildWriteBwc", "numSeconds"},
+	[]string{"normRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"normRebuildWriteBwc", "numOccured"},
+	[]string{"rebalanceReadBwc", "numSeconds"},
+	[]string{"rebalanceReadBwc", "totalWeightInKb"},
+	[]string{"rebalanceReadBwc", "numOccured"},
+	[]string{"rebalanceWriteBwc", "numSeconds"},
+	[]string{"rebalanceWriteBwc", "totalWeightInKb"},
+	[]string{"rebalanceWriteBwc", "numOccured"},
+}
+
+var sdsMetricKeys = [][]string{
+	[]string{"numOfDevices"},
+	[]string{"numOfRfcacheDevices"},
+	[]string{"BackgroundScanCompareCount"},
+	[]string{"BackgroundScannedInMB"},
+	[]string{"fixedReadErrorCount"},
+	[]string{"maxCapacityInKb"},
+	[]string{"capacityInUseInKb"},
+	[]string{"unusedCapacityInKb"},
+	[]string{"capacityLimitInKb"},
+	[]string{"thinCapacityInUseInKb"},
+	[]string{"thickCapacityInUseInKb"},
+	[]string{"snapCapacityInUseInKb"},
+	[]string{"snapCapacityInUseOccupiedInKb"},
+	[]string{"spareCapacityInKb"},
+	[]string{"protectedCapacityInKb"},
+	[]string{"failedCapacityInKb"},
+	[]string{"degradedHealthyCapacityInKb"},
+	[]string{"degradedFailedCapacityInKb"},
+	[]string{"inMaintenanceCapacityInKb"},
+	[]string{"semiProtectedCapacityInKb"},
+	[]string{"unreachableUnusedCapacityInKb"},
+	[]string{"rebalanceCapacityInKb"},
+	[]string{"pendingRebalanceCapacityInKb"},
+	[]string{"activeRebalanceCapacityInKb"},
+	[]string{"fwdRebuildCapacityInKb"},
+	[]string{"pendingFwdRebuildCapacityInKb"},
+	[]string{"activeFwdRebuildCapacityInKb"},
+	[]string{"bckRebuildCapacityInKb"},
+	[]string{"pendingBckRebuildCapacityInKb"},
+	[]string{"activeBckRebuildCapacityInKb"},
+	[]string{"normRebuildCapacityInKb"},
+	[]string{"pendingNormRebuildCapacityInKb"},
+	[]string{"activeNormRebuildCapacityInKb"},
+	[]string{"movingCapacityInKb"},
+	[]string{"activeMovingCapacityInKb"},
+	[]string{"pendingMovingCapacityInKb"},
+	[]string{"activeMovingInFwdRebuildJobs"},
+	[]string{"activeMovingOutFwdRebuildJobs"},
+	[]string{"activeMovingInBckRebuildJobs"},
+	[]string{"activeMovingOutBckRebuildJobs"},
+	[]string{"activeMovingInNormRebuildJobs"},
+	[]string{"activeMovingOutNormRebuildJobs"},
+	[]string{"activeMovingInRebalanceJobs"},
+	[]string{"activeMovingRebalanceJobs"},
+	[]string{"pendingMovingInFwdRebuildJobs"},
+	[]string{"pendingMovingOutFwdRebuildJobs"},
+	[]string{"pendingMovingInBckRebuildJobs"},
+	[]string{"pendingMovingOutBckRebuildJobs"},
+	[]string{"pendingMovingInNormRebuildJobs"},
+	[]string{"pendingMovingOutNormrebuildJobs"},
+	[]string{"pendingMovingInRebalanceJobs"},
+	[]string{"pendingMovingRebalanceJobs"},
+	[]string{"primaryReadBwc", "numSeconds"},
+	[]string{"primaryReadBwc", "totalWeightInKb"},
+	[]string{"primaryReadBwc", "numOccured"},
+	[]string{"primaryWriteBwc", "numSeconds"},
+	[]string{"primaryWriteBwc", "totalWeightInKb"},
+	[]string{"primaryWriteBwc", "numOccured"},
+	[]string{"secondaryReadBwc", "numSeconds"},
+	[]string{"secondaryReadBwc", "totalWeightInKb"},
+	[]string{"secondaryReadBwc", "numOccured"},
+	[]string{"secondaryWriteBwc", "numSeconds"},
+	[]string{"secondaryWriteBwc", "totalWeightInKb"},
+	[]string{"secondaryWriteBwc", "numOccured"},
+	[]string{"totalReadBwc", "numSeconds"},
+	[]string{"totalReadBwc", "totalWeightInKb"},
+	[]string{"totalReadBwc", "numOccured"},
+	[]string{"totalWriteBwc", "numSeconds"},
+	[]string{"totalWriteBwc", "totalWeightInKb"},
+	[]string{"totalWriteBwc", "numOccured"},
+	[]string{"primaryReadFromDevBwc", "numSeconds"},
+	[]string{"primaryReadFromDevBwc", "totalWeightInKb"},
+	[]string{"primaryReadFromDevBwc", "numOccured"},
+	[]string{"secondaryReadFromDevBwc", "numSeconds"},
+	[]string{"secondaryReadFromDevBwc", "totalWeightInKb"},
+	[]string{"secondaryReadFromDevBwc", "numOccured"},
+	[]string{"primaryReadFromRmcacheBwc", "numSeconds"},
+	[]string{"primaryReadFromRmcacheBwc", "totalWeightInKb"},
+	[]string{"primaryReadFromRmcacheBwc", "numOccured"},
+	[]string{"secondaryReadFromRmcacheBwc", "numSeconds"},
+	[]string{"secondaryReadFromRmcacheBwc", "totalWeightInKb"},
+	[]string{"secondaryReadFromRmcacheBwc", "numOccured"},
+	[]string{"fwdRebuildReadBwc", "numSeconds"},
+	[]string{"fwdRebuildReadBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildReadBwc", "numOccured"},
+	[]string{"fwdRebuildWriteBwc", "numSeconds"},
+	[]string{"fwdRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildWriteBwc", "numOccured"},
+	[]string{"bckRebuildReadBwc", "numSeconds"},
+	[]string{"bckRebuildReadBwc", "totalWeightInKb"},
+	[]string{"bckRebuildReadBwc", "numOccured"},
+	[]string{"bckRebuildWriteBwc", "numSeconds"},
+	[]string{"bckRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"bckRebuildWriteBwc", "numOccured"},
+	[]string{"normRebuildReadBwc", "numSeconds"},
+	[]string{"normRebuildReadBwc", "totalWeightInKb"},
+	[]string{"normRebuildReadBwc", "numOccured"},
+	[]string{"normRebuildWriteBwc", "numSeconds"},
+	[]string{"normRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"normRebuildWriteBwc", "numOccured"},
+	[]string{"rebalanceReadBwc", "numSeconds"},
+	[]string{"rebalanceReadBwc", "totalWeightInKb"},
+	[]string{"rebalanceReadBwc", "numOccured"},
+	[]string{"rebalanceWriteBwc", "numSeconds"},
+	[]string{"rebalanceWriteBwc", "totalWeightInKb"},
+	[]string{"rebalanceWriteBwc", "numOccured"},
+	[]string{"rmcacheSizeInKb"},
+	[]string{"rmcacheSizeInUseInKb"},
+	[]string{"rmcacheEntryEvictionCount"},
+	[]string{"rmcacheBigBlockEvictionCount"},
+	[]string{"rmcacheNoEvictionCount"},
+	[]string{"rmcacheSkipCountLargeIo"},
+	[]string{"rmcacheSkipCountUnaligned4kbIo"},
+	[]string{"rmcacheSkipCountCacheAllBusy"},
+	[]string{"rfcacheReadsReceived"},
+	[]string{"rfcacheWritesReceived"},
+	[]string{"rfcacheReadsFromCache"},
+	[]string{"rfacheReadHit"},
+	[]string{"rfcacheReadMiss"},
+	[]string{"rfcacheWriteMiss"},
+	[]string{"rfcacheIosSkipped"},
+	[]string{"rfcacheIoErrors"},
+	[]string{"rfcacheIosOutstanding"},
+	[]string{"rfcacheReadsPending"},
+	[]string{"rfcacheWritePending"},
+	[]string{"rfcacheAvgReadTime"},
+	[]string{"rfcacheAvgWriteTime"},
+	[]string{"rfcacheSourceDeviceReads"},
+	[]string{"rfcacheSourceDeviceWrites"},
+	[]string{"rfcacheFdReadsReceived"},
+	[]string{"rfcacheFdWritesReceived"},
+	[]string{"rfcacheFdAvgReadTime"},
+	[]string{"rfcacheFdAvgWriteTime"},
+	[]string{"rfcacheFdIoErrors"},
+	[]string{"rfcacheFdInlightReads"},
+	[]string{"rfcacheFdInlightWrites"},
+	[]string{"rfcacheFdCacheOverloaded"},
+	[]string{"rfcachePoolSize"},
+	[]string{"rfcachePoolInUse"},
+	[]string{"userDataSdcReadLatency", "numSeconds"},
+	[]string{"userDataSdcReadLatency", "totalWeightInKb"},
+	[]string{"userDataSdcReadLatency", "numOccured"},
+	[]string{"userDataSdcWriteLatency", "numSeconds"},
+	[]string{"userDataSdcWriteLatency", "totalWeightInKb"},
+	[]string{"userDataSdcWriteLatency", "numOccured"},
+	[]string{"userDataSdcTrimLatency", "numSeconds"},
+	[]string{"userDataSdcTrimLatency", "totalWeightInKb"},
+	[]string{"userDataSdcTrimLatency", "numOccured"},
+}
+
+var sdcMetricKeys = [][]string{
+	[]string{"numOfMappedVolumes"},
+	[]string{"userDataReadBwc", "numSeconds"},
+	[]string{"userDataReadBwc", "totalWeightInKb"},
+	[]string{"userDataReadBwc", "numOccured"},
+	[]string{"userDataWriteBwc", "numSeconds"},
+	[]string{"userDataWriteBwc", "totalWeightInKb"},
+	[]string{"userDataWriteBwc", "numOccured"},
+	[]string{"userDataTrimBwc", "numSeconds"},
+	[]string{"userDataTrimBwc", "totalWeightInKb"},
+	[]string{"userDataTrimBwc", "numOccured"},
+	[]string{"userDataSdcReadLatency", "numSeconds"},
+	[]string{"userDataSdcReadLatency", "totalWeightInKb"},
+	[]string{"userDataSdcReadLatency", "numOccured"},
+	[]string{"userDataSdcWriteLatency", "numSeconds"},
+	[]string{"userDataSdcWriteLatency", "totalWeightInKb"},
+	[]string{"userDataSdcWriteLatency", "numOccured"},
+	[]string{"userDataSdcTrimLatency", "numSeconds"},
+	[]string{"userDataSdcTrimLatency", "totalWeightInKb"},
+	[]string{"userDataSdcTrimLatency", "numOccured"},
+}
+
+var volumeMetricKeys = [][]string{
+	[]string{"numOfMappedSdcs"},
+	[]string{"numOfChildVolumes"},
+	[]string{"numOfDescendantVolumes"},
+	[]string{"userDataReadBwc", "numSeconds"},
+	[]string{"userDataReadBwc", "totalWeightInKb"},
+	[]string{"userDataReadBwc", "numOccured"},
+	[]string{"userDataWriteBwc", "numSeconds"},
+	[]string{"userDataWriteBwc", "totalWeightInKb"},
+	[]string{"userDataWriteBwc", "numOccured"},
+	[]string{"userDataTrimBwc", "numSeconds"},
+	[]string{"userDataTrimBwc", "totalWeightInKb"},
+	[]string{"userDataTrimBwc", "numOccured"},
+	[]string{"userDataSdcReadLatency", "numSeconds"},
+	[]string{"userDataSdcReadLatency", "totalWeightInKb"},
+	[]string{"userDataSdcReadLatency", "numOccured"},
+	[]string{"userDataSdcWriteLatency", "numSeconds"},
+	[]string{"userDataSdcWriteLatency", "totalWeightInKb"},
+	[]string{"userDataSdcWriteLatency", "numOccured"},
+	[]string{"userDataSdcTrimLatency", "numSeconds"},
+	[]string{"userDataSdcTrimLatency", "totalWeightInKb"},
+	[]string{"userDataSdcTrimLatency", "numOccured"},
+}
+
+var deviceMetricKeys = [][]string{
+	[]string{"deviceState"},
+	[]string{"errorState"},
+	[]string{"avgReadLatencyInMicrosec"},
+	[]string{"avgWriteLatencyInMicrosec"},
+	[]string{"avgReadSizeInBytes"},
+	[]string{"avgWriteSizeInBytes"},
+	[]string{"maxCapacityInKb"},
+	[]string{"capacityInUseInKb"},
+	[]string{"unusedCapacityInKb"},
+	[]string{"capacityLimitInKb"},
+	[]string{"thinCapacityInUseInKb"},
+	[]string{"thickCapacityInUseInKb"},
+	[]string{"snapCapacityInUseInKb"},
+	[]string{"snapCapacityInUseOccupiedInKb"},
+	[]string{"spareCapacityInKb"},
+	[]string{"protectedCapacityInKb"},
+	[]string{"failedCapacityInKb"},
+	[]string{"degradedHealthyCapacityInKb"},
+	[]string{"degradedFailedCapacityInKb"},
+	[]string{"inMaintenanceCapacityInKb"},
+	[]string{"semiProtectedCapacityInKb"},
+	[]string{"unreachableUnusedCapacityInKb"},
+	[]string{"fixedReadErrorCount"},
+	[]string{"BackgroundScanCompareCount"},
+	[]string{"BackgroundScannedInMB"},
+	[]string{"BackgroundScanFixedReadErrorCount"},
+	[]string{"BackgroundScanFixedCompareErrorCount"},
+	[]string{"primaryReadBwc", "numSeconds"},
+	[]string{"primaryReadBwc", "totalWeightInKb"},
+	[]string{"primaryReadBwc", "numOccured"},
+	[]string{"primaryWriteBwc", "numSeconds"},
+	[]string{"primaryWriteBwc", "totalWeightInKb"},
+	[]string{"primaryWriteBwc", "numOccured"},
+	[]string{"secondaryReadBwc", "numSeconds"},
+	[]string{"secondaryReadBwc", "totalWeightInKb"},
+	[]string{"secondaryReadBwc", "numOccured"},
+	[]string{"secondaryWriteBwc", "numSeconds"},
+	[]string{"secondaryWriteBwc", "totalWeightInKb"},
+	[]string{"secondaryWriteBwc", "numOccured"},
+	[]string{"totalReadBwc", "numSeconds"},
+	[]string{"totalReadBwc", "totalWeightInKb"},
+	[]string{"totalReadBwc", "numOccured"},
+	[]string{"totalWriteBwc", "numSeconds"},
+	[]string{"totalWriteBwc", "totalWeightInKb"},
+	[]string{"totalWriteBwc", "numOccured"},
+	[]string{"primaryReadFromDevBwc", "numSeconds"},
+	[]string{"primaryReadFromDevBwc", "totalWeightInKb"},
+	[]string{"primaryReadFromDevBwc", "numOccured"},
+	[]string{"secondaryReadFromDevBwc", "numSeconds"},
+	[]string{"secondaryReadFromDevBwc", "totalWeightInKb"},
+	[]string{"secondaryReadFromDevBwc", "numOccured"},
+	[]string{"fwdRebuildReadBwc", "numSeconds"},
+	[]string{"fwdRebuildReadBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildReadBwc", "numOccured"},
+	[]string{"fwdRebuildWriteBwc", "numSeconds"},
+	[]string{"fwdRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildWriteBwc", "numOccured"},
+	[]string{"bckRebuildReadBwc", "numSeconds"},
+	[]string{"bckRebuildReadBwc", "totalWeightInKb"},
+	[]string{"bckRebuildReadBwc", "numOccured"},
+	[]string{"bckRebuildWriteBwc", "numSeconds"},
+	[]string{"bckRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"bckRebuildWriteBwc", "numOccured"},
+	[]string{"normRebuildReadBwc", "numSeconds"},
+	[]string{"normRebuildReadBwc", "totalWeightInKb"},
+	[]string{"normRebuildReadBwc", "numOccured"},
+	[]string{"normRebuildWriteBwc", "numSeconds"},
+	[]string{"normRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"normRebuildWriteBwc", "numOccured"},
+	[]string{"rebalanceReadBwc", "numSeconds"},
+	[]string{"rebalanceReadBwc", "totalWeightInKb"},
+	[]string{"rebalanceReadBwc", "numOccured"},
+	[]string{"rebalanceWriteBwc", "numSeconds"},
+	[]string{"rebalanceWriteBwc", "totalWeightInKb"},
+	[]string{"rebalanceWriteBwc", "numOccured"},
+}
+
+var faultSetMetricKeys = [][]string{
+	[]string{"numOfSds"},
+	[]string{"numOfDevices"},
+	[]string{"numOfRfcacheDevices"},
+	[]string{"maxCapacityInKb"},
+	[]string{"capacityInUseInKb"},
+	[]string{"unusedCapacityInKb"},
+	[]string{"capacityLimitInKb"},
+	[]string{"thinCapacityInUseInKb"},
+	[]string{"thickCapacityInUseInKb"},
+	[]string{"snapCapacityInUseInKb"},
+	[]string{"snapCapacityInUseOccupiedInKb"},
+	[]string{"spareCapacityInKb"},
+	[]string{"protectedCapacityInKb"},
+	[]string{"failedCapacityInKb"},
+	[]string{"degradedHealthyCapacityInKb"},
+	[]string{"degradedFailedCapacityInKb"},
+	[]string{"inMaintenanceCapacityInKb"},
+	[]string{"semiProtectedCapacityInKb"},
+	[]string{"unreachableUnusedCapacityInKb"},
+	[]string{"rebalanceCapacityInKb"},
+	[]string{"pendingRebalanceCapacityInKb"},
+	[]string{"activeRebalanceCapacityInKb"},
+	[]string{"fwdRebuildCapacityInKb"},
+	[]string{"pendingFwdRebuildCapacityInKb"},
+	[]string{"activeFwdRebuildCapacityInKb"},
+	[]string{"bckRebuildCapacityInKb"},
+	[]string{"pendingBckRebuildCapacityInKb"},
+	[]string{"activeBckRebuildCapacityInKb"},
+	[]string{"normRebuildCapacityInKb"},
+	[]string{"pendingNormRebuildCapacityInKb"},
+	[]string{"activeNormRebuildCapacityInKb"},
+	[]string{"movingCapacityInKb"},
+	[]string{"activeMovingCapacityInKb"},
+	[]string{"pendingMovingCapacityInKb"},
+	[]string{"primaryReadBwc", "numSeconds"},
+	[]string{"primaryReadBwc", "totalWeightInKb"},
+	[]string{"primaryReadBwc", "numOccured"},
+	[]string{"primaryWriteBwc", "numSeconds"},
+	[]string{"primaryWriteBwc", "totalWeightInKb"},
+	[]string{"primaryWriteBwc", "numOccured"},
+	[]string{"secondaryReadBwc", "numSeconds"},
+	[]string{"secondaryReadBwc", "totalWeightInKb"},
+	[]string{"secondaryReadBwc", "numOccured"},
+	[]string{"secondaryWriteBwc", "numSeconds"},
+	[]string{"secondaryWriteBwc", "totalWeightInKb"},
+	[]string{"secondaryWriteBwc", "numOccured"},
+	[]string{"totalReadBwc", "numSeconds"},
+	[]string{"totalReadBwc", "totalWeightInKb"},
+	[]string{"totalReadBwc", "numOccured"},
+	[]string{"totalWriteBwc", "numSeconds"},
+	[]string{"totalWriteBwc", "totalWeightInKb"},
+	[]string{"totalWriteBwc", "numOccured"},
+	[]string{"fwdRebuildReadBwc", "numSeconds"},
+	[]string{"fwdRebuildReadBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildReadBwc", "numOccured"},
+	[]string{"fwdRebuildWriteBwc", "numSeconds"},
+	[]string{"fwdRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"fwdRebuildWriteBwc", "numOccured"},
+	[]string{"bckRebuildReadBwc", "numSeconds"},
+	[]string{"bckRebuildReadBwc", "totalWeightInKb"},
+	[]string{"bckRebuildReadBwc", "numOccured"},
+	[]string{"bckRebuildWriteBwc", "numSeconds"},
+	[]string{"bckRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"bckRebuildWriteBwc", "numOccured"},
+	[]string{"normRebuildReadBwc", "numSeconds"},
+	[]string{"normRebuildReadBwc", "totalWeightInKb"},
+	[]string{"normRebuildReadBwc", "numOccured"},
+	[]string{"normRebuildWriteBwc", "numSeconds"},
+	[]string{"normRebuildWriteBwc", "totalWeightInKb"},
+	[]string{"normRebuildWriteBwc", "numOccured"},
+	[]string{"rebalanceReadBwc", "numSeconds"},
+	[]string{"rebalanceReadBwc", "totalWeightInKb"},
+	[]string{"rebalanceReadBwc", "numOccured"},
+	[]string{"rebalanceWriteBwc", "numSeconds"},
+	[]string{"rebalanceWriteBwc", "totalWeightInKb"},
+	[]string{"rebalanceWriteBwc", "numOccured"},
+}
+
+var rfcacheDeviceMetricKeys = [][]string{
+	[]string{"rfcacheFdReadsReceived"},
+	[]string{"rfcacheFdWritesReceived"},
+	[]string{"rfcacheFdReadHit"},
+	[]string{"rfcacheFdReadMiss"},
+	[]string{"rfcacheFdWriteHit"},
+	[]string{"rfcacheFdWriteMiss"},
+	[]string{"rfcacheFdReadsSkipped"},
+	[]string{"rfcacheFdWritesSkipped"},
+	[]string{"rfcacheFdAvgReadTime"},
+	[]string{"rfcacheFdAvgWriteTime"},
+	[]string{"rfcacheFdIoErrors"},
+	[]string{"rfcacheFdInlightReads"},
+	[]string{"rfcacheFdInlightWrites"},
+	[]string{"rfcacheFdCacheOverloaded"},
+	[]string{"rfcacheFdMonitorErrorStuckIo"},
+	[]string{"rfcacheFdReadTimeGreater1Min"},
+	[]string{"rfcacheFdReadTimeGreater1Sec"},
+	[]string{"rfcacheFdReadTimeGreater500Millis"},
+	[]string{"rfcacheFdReadTimeGreater5Sec"},
+	[]string{"rfcacheFdWriteTimeGreater1Min"},
+	[]string{"rfcacheFdWriteTimeGreater1Sec"},
+	[]string{"rfcacheFdWriteTimeGreater500Millis"},
+	[]string{"rfcacheFdWriteTimeGreater5Sec"},
+}
+
+var vtreeMetricKeys = [][]string{
+	[]string{"numOfSnapshots"},
+	[]string{"numOfVolumes"},
+	[]string{"netCapacityInUseInKb"},
+	[]string{"baseNetCapacityInUseInKb"},
+	[]string{"snapNetCapacityInUseInKb"},
+	[]string{"trimmedCapacityInKb"},
+}
+
+var mdmMetricKeys = [][]string{
+	[]string{"clusterMode"},
+	[]string{"clusterState"},
+	[]string{"goodNodes"},
+	[]string{"goodReplicas"},
+	[]string{"activeMembers"},
+	[]string{"standbyMembers"},
 }
@@ -0,0 +1,152 @@
+package scaleio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+const (
+	// mdmMemberIdx is the position of the dynamic member ID in
+	// /intel/scaleio/mdm/member/<ID>/state
+	mdmMemberIdx = 4
+	// unknownState is reported for states missing from the tables below
+	unknownState = -1
+)
+
+// mdmClusterModes maps the MDM cluster mode to the number of cluster nodes
+var mdmClusterModes = map[string]int{
+	"OneNode":    1,
+	"ThreeNodes": 3,
+	"FiveNodes":  5,
+}
+
+// mdmClusterStates maps the MDM cluster state to a gauge, 0 being healthy
+var mdmClusterStates = map[string]int{
+	"ClusteredNormal":                 0,
+	"ClusteredDegraded":               1,
+	"ClusteredTiebreakerDown":         2,
+	"ClusteredDegradedTiebreakerDown": 3,
+	"NotClustered":                    4,
+}
+
+// mdmMemberStates maps the state of a single MDM to a gauge, 0 being healthy
+var mdmMemberStates = map[string]int{
+	"Normal":       0,
+	"Degraded":     1,
+	"Disconnected": 2,
+}
+
+type mdmMember struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Status string `json:"status"`
+}
+
+type mdmCluster struct {
+	ClusterMode     string      `json:"clusterMode"`
+	ClusterState    string      `json:"clusterState"`
+	GoodNodesNum    int         `json:"goodNodesNum"`
+	GoodReplicasNum int         `json:"goodReplicasNum"`
+	Master          mdmMember   `json:"master"`
+	Slaves          []mdmMember `json:"slaves"`
+	TieBreakers     []mdmMember `json:"tieBreakers"`
+	StandbyMDMs     []mdmMember `json:"standbyMDMs"`
+}
+
+// mdmMetrics collects the state of the MDM cluster and of each of its members
+func (s *ScaleIO) mdmMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	results := []plugin.Metric{}
+	if len(nss) == 0 {
+		return results, nil
+	}
+
+	var cluster mdmCluster
+	err := client.PostAPIResponse(ctx, queryMdmClusterPath, map[string]string{}, &cluster)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	// The member type tells whether an MDM is part of the active cluster
+	type typedMember struct {
+		mdmMember
+		memberType string
+	}
+	members := []typedMember{{cluster.Master, "master"}}
+	for _, m := range cluster.Slaves {
+		members = append(members, typedMember{m, "slave"})
+	}
+	for _, m := range cluster.TieBreakers {
+		members = append(members, typedMember{m, "tieBreaker"})
+	}
+	for _, m := range cluster.StandbyMDMs {
+		members = append(members, typedMember{m, "standby"})
+	}
+
+	for _, ns := range nss {
+		key := ns.Strings()[3:]
+		if len(key) == 3 && key[0] == "member" && key[2] == "state" {
+			for _, m := range members {
+				// Skip members other than the one asked for, if any
+				if !matchesInstance(ns[mdmMemberIdx].Value, map[string]interface{}{"id": m.ID, "name": m.Name}) {
+					continue
+				}
+				dyn := make([]plugin.NamespaceElement, len(ns))
+				copy(dyn, ns)
+				dyn[mdmMemberIdx].Value = m.ID
+				results = append(results, plugin.Metric{
+					Namespace: dyn,
+					Timestamp: now,
+					Data:      stateCode(mdmMemberStates, m.Status),
+					Tags: withoutEmptyTags(map[string]string{
+						"memberName": m.Name,
+						"role":       m.Role,
+						"memberType": m.memberType,
+					}),
+				})
+			}
+			continue
+		}
+		if len(key) != 1 {
+			return nil, fmt.Errorf("Invalid metric namespace given: %v", ns)
+		}
+
+		var data interface{}
+		switch key[0] {
+		case "clusterMode":
+			data = stateCode(mdmClusterModes, cluster.ClusterMode)
+		case "clusterState":
+			data = stateCode(mdmClusterStates, cluster.ClusterState)
+		case "goodNodes":
+			data = cluster.GoodNodesNum
+		case "goodReplicas":
+			data = cluster.GoodReplicasNum
+		case "activeMembers":
+			data = 1 + len(cluster.Slaves) + len(cluster.TieBreakers)
+		case "standbyMembers":
+			data = len(cluster.StandbyMDMs)
+		default:
+			return nil, fmt.Errorf("Invalid metric namespace given: %v", ns)
+		}
+		results = append(results, plugin.Metric{
+			Namespace: ns,
+			Timestamp: now,
+			Data:      data,
+		})
+	}
+	return results, nil
+}
+
+// stateCode returns the gauge value of a state, or unknownState
+func stateCode(codes map[string]int, state string) int {
+	code, ok := codes[state]
+	if !ok {
+		return unknownState
+	}
+	return code
+}
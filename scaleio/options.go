@@ -0,0 +1,66 @@
+package scaleio
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// getClientOptions returns the gateway connection options set in the config.
+// Settings missing from the config keep their default value.
+func getClientOptions(cfg plugin.Config) (sioclient.Options, error) {
+	opts := sioclient.DefaultOptions
+	for key, timeout := range map[string]*time.Duration{
+		"connectTimeout": &opts.ConnectTimeout,
+		"requestTimeout": &opts.RequestTimeout,
+	} {
+		if err := getDuration(cfg, key, timeout); err != nil {
+			return opts, err
+		}
+	}
+	for key, value := range map[string]*string{
+		"caCertPath":     &opts.CACertPath,
+		"clientCertPath": &opts.ClientCertPath,
+		"clientKeyPath":  &opts.ClientKeyPath,
+		"serverName":     &opts.ServerName,
+		"proxy":          &opts.Proxy,
+	} {
+		if v, err := cfg.GetString(key); err == nil {
+			*value = v
+		}
+	}
+	return opts, nil
+}
+
+// collectContext returns the context bounding a whole collection, or
+// discovery, according to the collectTimeout set in the config
+func collectContext(cfg plugin.Config) (context.Context, context.CancelFunc, error) {
+	var timeout time.Duration
+	if err := getDuration(cfg, "collectTimeout", &timeout); err != nil {
+		return nil, nil, err
+	}
+	if timeout <= 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, cancel, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return ctx, cancel, nil
+}
+
+// getDuration parses the duration stored under key in the config into d. d is
+// left unchanged if the config does not hold key.
+func getDuration(cfg plugin.Config, key string, d *time.Duration) error {
+	value, err := cfg.GetString(key)
+	if err != nil {
+		return nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("Invalid %s %q: %v", key, value, err)
+	}
+	*d = parsed
+	return nil
+}
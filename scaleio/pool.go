@@ -1,66 +1,134 @@
 package scaleio
 
 import (
-	"fmt"
+	"context"
+	"log"
 	"time"
 
 	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
 	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
 )
 
-const (
-	storagePoolIDIdx = 3
-)
+// discoveryTimeout bounds the listing of the storage pools in GetMetricTypes,
+// including the retries
+const discoveryTimeout = 5 * time.Second
 
-func (s *ScaleIO) poolMetrics(client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+// poolMetricKeys returns the keys of the pool statistics along with the keys
+// of the pool derived groups and forecast
+func poolMetricKeys() [][]string {
+	keys := append([][]string{}, storagePoolMetricKeys...)
+	keys = append(keys, derivedGroupKeys(storagePoolType)...)
+	return append(keys, []string{forecastGroup, daysUntilFullKey})
+}
 
-	results := []plugin.Metric{}
+// poolMetricTypes returns the pool metric types of every storage pool that
+// exists on the gateway, with the pool ID filled in. Nothing is returned if
+// the config does not hold the gateway credentials, or if the pools cannot be
+// listed within discoveryTimeout, as these types only complement the static
+// ones.
+func (s *ScaleIO) poolMetricTypes(cfg plugin.Config) []plugin.Metric {
+	mts := []plugin.Metric{}
+	for _, key := range []string{"gateway", "username", "password"} {
+		if _, err := cfg.GetString(key); err != nil {
+			return mts
+		}
+	}
+	if _, err := cfg.GetBool("verifySSL"); err != nil {
+		// The config policy defaults are not applied to this config
+		withDefaults := plugin.Config{"verifySSL": true}
+		for k, v := range cfg {
+			withDefaults[k] = v
+		}
+		cfg = withDefaults
+	}
+	client, err := s.GetSIOClient(cfg)
+	if err != nil {
+		log.Printf("Skipping storage pool discovery: %v", err)
+		return mts
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), discoveryTimeout)
+	defer cancel()
+	err = client.Authenticate(ctx)
+	if err != nil {
+		log.Printf("Skipping storage pool discovery, failed to authenticate SIO API Client: %v", err)
+		return mts
+	}
+	pools, err := listInstances(ctx, client, storagePoolType)
+	if err != nil {
+		log.Printf("Skipping storage pool discovery: %v", err)
+		return mts
+	}
+	for _, pool := range pools {
+		id := stringField(pool, "id")
+		for _, keys := range withDerivedKeys(catalogKeys(poolMetricKeys(), normalizeUnits(cfg))) {
+			namespace := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP, id).AddStaticElements(keys...)
+			mts = append(mts, plugin.Metric{Namespace: namespace})
+		}
+	}
+	return mts
+}
 
-	// Everything is dynamic right now so get the list of all the StoragePools
-	var pools []map[string]interface{}
-	err := client.GetAPIResponse(storagePoolPath, &pools)
+// poolMetrics collects the Statistics of every StoragePool, tagged with the
+// pool topology and settings. Pools can be selected either by ID or by name.
+func (s *ScaleIO) poolMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace, gateway string, forecastWindow time.Duration) ([]plugin.Metric, error) {
+	if len(nss) == 0 {
+		return []plugin.Metric{}, nil
+	}
+	// Pools only reference their protection domain, so map the protection
+	// domains to their system
+	pds, err := listInstances(ctx, client, pdType)
 	if err != nil {
 		return nil, err
 	}
-	now := time.Now()
-	for _, v := range pools {
-		id, ok := v["id"].(string)
-		if !ok {
-			return nil, fmt.Errorf("Found StoragePool entry without an ID")
+	pdSystems := make(map[string]string, len(pds))
+	for _, pd := range pds {
+		pdSystems[stringField(pd, "id")] = stringField(pd, "systemId")
+	}
+	tagger := func(pool map[string]interface{}) map[string]string {
+		pdID := stringField(pool, "protectionDomainId")
+		return map[string]string{
+			"storagePoolName":          stringField(pool, "name"),
+			"protectionDomainId":       pdID,
+			"systemId":                 pdSystems[pdID],
+			"mediaType":                stringField(pool, "mediaType"),
+			"zeroPaddingEnabled":       boolField(pool, "zeroPaddingEnabled"),
+			"useRmcache":               boolField(pool, "useRmcache"),
+			"rmcacheWriteHandlingMode": stringField(pool, "rmcacheWriteHandlingMode"),
+			"useRfcache":               boolField(pool, "useRfcache"),
 		}
-		var metrics map[string]interface{}
-		err := client.GetAPIResponse(fmt.Sprintf(statisticsPath, id), &metrics)
-		if err != nil {
-			return nil, err
+	}
+	statNss := []plugin.Namespace{}
+	forecastNss := []plugin.Namespace{}
+	for _, ns := range nss {
+		if len(ns) == instanceIDIdx+3 && ns[instanceIDIdx+1].Value == forecastGroup {
+			forecastNss = append(forecastNss, ns)
+			// The forecast inputs are fetched along with the other statistics
+			statNss = append(statNss,
+				forecastInput(ns, "capacityInUseInKb"),
+				forecastInput(ns, "maxCapacityInKb"))
+		} else {
+			statNss = append(statNss, ns)
 		}
-		for _, ns := range nss {
-			// Slice out only the important part for now
-			dyn := make([]plugin.NamespaceElement, len(ns))
-			copy(dyn, ns)
-			dyn[storagePoolIDIdx].Value = id
-
-			currentNamespace := ns.Strings()[storagePoolIDIdx+1:]
-			var data interface{}
-			if len(currentNamespace) == 1 {
-				data = metrics[currentNamespace[0]]
-			} else if len(currentNamespace) == 2 {
-				subMap, ok := metrics[currentNamespace[0]].(map[string]interface{})
-				if !ok {
-					return nil, fmt.Errorf("Invalid data found for %s with on StoragePool %s", ns, id)
-				}
-				data = subMap[currentNamespace[1]]
-			} else {
-				return nil, fmt.Errorf("Invalid metric namespace given: %v", ns)
-			}
-
-			newMetric := plugin.Metric{
-				Namespace: dyn,
-				Timestamp: now,
-				Data:      data,
-			}
-			results = append(results, newMetric)
+	}
+	statMts, err := statisticsMetrics(ctx, client, storagePoolType, statNss, tagger)
+	if err != nil {
+		return nil, err
+	}
+	mts := []plugin.Metric{}
+	inputs := []plugin.Metric{}
+	for _, m := range statMts {
+		if isForecastInput(m.Namespace) {
+			inputs = append(inputs, m)
+		} else {
+			mts = append(mts, m)
 		}
 	}
-
-	return results, nil
+	if len(forecastNss) == 0 {
+		return mts, nil
+	}
+	forecastMts, err := s.forecastMetrics(forecastNss, inputs, gateway, forecastWindow)
+	if err != nil {
+		return nil, err
+	}
+	return append(mts, forecastMts...), nil
 }
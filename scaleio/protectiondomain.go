@@ -0,0 +1,19 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// protectionDomainMetrics collects the Statistics of every ProtectionDomain
+func (s *ScaleIO) protectionDomainMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	tagger := func(pd map[string]interface{}) map[string]string {
+		return map[string]string{
+			"protectionDomainName": stringField(pd, "name"),
+			"systemId":             stringField(pd, "systemId"),
+		}
+	}
+	return statisticsMetrics(ctx, client, pdType, nss, tagger)
+}
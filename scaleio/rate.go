@@ -0,0 +1,94 @@
+package scaleio
+
+import (
+	"strings"
+	"time"
+
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// countUnit is the unit of the rate of a counter without unit
+const countUnit = "1"
+
+// counterSample is the last value seen of a monotonic counter
+type counterSample struct {
+	timestamp time.Time
+	value     float64
+}
+
+// isCounter tells whether the metric key is a monotonic counter, or its
+// conversion to bytes
+func isCounter(key string) bool {
+	source, _, _ := sourceKey(key)
+	return counterKeys[source]
+}
+
+// counterPattern returns the key of the samples of a counter of every
+// instance: the gateway and the namespace with a wildcard instance ID
+func counterPattern(gateway string, ns plugin.Namespace) string {
+	elements := ns.Strings()
+	elements[instanceIDIdx] = "*"
+	return gateway + " /" + strings.Join(elements, "/")
+}
+
+// rates converts the monotonic counters among mts to per-second rates since
+// their previous sample. A counter is dropped the first time it is seen and
+// whenever it was reset, since there is no previous value to compare with.
+// The samples of the instances missing from the result of a wildcard request
+// are forgotten, as the instances are gone.
+func (s *ScaleIO) rates(gateway string, requested []plugin.Namespace, mts []plugin.Metric) []plugin.Metric {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	seen := map[string]map[string]counterSample{}
+	results := make([]plugin.Metric, 0, len(mts))
+	for _, m := range mts {
+		ns := m.Namespace
+		if len(ns) != instanceIDIdx+2 || !isCounter(ns[instanceIDIdx+1].Value) {
+			results = append(results, m)
+			continue
+		}
+		value, ok := m.Data.(float64)
+		if !ok {
+			results = append(results, m)
+			continue
+		}
+		pattern := counterPattern(gateway, ns)
+		id := ns[instanceIDIdx].Value
+		if s.counterSamples[pattern] == nil {
+			s.counterSamples[pattern] = map[string]counterSample{}
+		}
+		if seen[pattern] == nil {
+			seen[pattern] = map[string]counterSample{}
+		}
+		previous, found := s.counterSamples[pattern][id]
+		sample := counterSample{m.Timestamp, value}
+		s.counterSamples[pattern][id] = sample
+		seen[pattern][id] = sample
+
+		elapsed := m.Timestamp.Sub(previous.timestamp).Seconds()
+		if !found || value < previous.value || elapsed <= 0 {
+			continue
+		}
+		m.Data = (value - previous.value) / elapsed
+		unit := m.Unit
+		if unit == "" {
+			unit = countUnit
+		}
+		m.Unit = unit + "/s"
+		results = append(results, m)
+	}
+
+	for _, ns := range requested {
+		if len(ns) != instanceIDIdx+2 || ns[instanceIDIdx].Value != "*" || !isCounter(ns[instanceIDIdx+1].Value) {
+			continue
+		}
+		pattern := counterPattern(gateway, ns)
+		if seen[pattern] == nil {
+			delete(s.counterSamples, pattern)
+		} else {
+			s.counterSamples[pattern] = seen[pattern]
+		}
+	}
+	return results
+}
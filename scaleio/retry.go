@@ -0,0 +1,50 @@
+package scaleio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// defaultRetryStatusCodes are the status codes retried by default, as a
+// comma separated list
+const defaultRetryStatusCodes = "502,503,504"
+
+// getRetryPolicy returns the client retry policy set in the config. Settings
+// missing from the config keep their default value.
+func getRetryPolicy(cfg plugin.Config) (sioclient.RetryPolicy, error) {
+	p := sioclient.DefaultRetryPolicy
+	if attempts, err := cfg.GetInt("retryMaxAttempts"); err == nil {
+		p.MaxAttempts = int(attempts)
+	}
+	for key, delay := range map[string]*time.Duration{
+		"retryBaseDelay": &p.BaseDelay,
+		"retryMaxDelay":  &p.MaxDelay,
+	} {
+		if err := getDuration(cfg, key, delay); err != nil {
+			return p, err
+		}
+	}
+	if codes, err := cfg.GetString("retryStatusCodes"); err == nil {
+		p.RetryableStatusCodes = []int{}
+		for _, code := range strings.Split(codes, ",") {
+			code = strings.TrimSpace(code)
+			if code == "" {
+				continue
+			}
+			c, err := strconv.Atoi(code)
+			if err != nil {
+				return p, fmt.Errorf("Invalid retryStatusCodes %q: %v", codes, err)
+			}
+			p.RetryableStatusCodes = append(p.RetryableStatusCodes, c)
+		}
+	}
+	if retry, err := cfg.GetBool("retryConnectionErrors"); err == nil {
+		p.RetryConnectionErrors = retry
+	}
+	return p, nil
+}
@@ -0,0 +1,19 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// rfcacheDeviceMetrics collects the Statistics of every RFcache device
+func (s *ScaleIO) rfcacheDeviceMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	tagger := func(device map[string]interface{}) map[string]string {
+		return map[string]string{
+			"rfcacheDeviceName": stringField(device, "name"),
+			"sdsId":             stringField(device, "sdsId"),
+		}
+	}
+	return statisticsMetrics(ctx, client, rfcacheDeviceType, nss, tagger)
+}
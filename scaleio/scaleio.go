@@ -20,33 +20,67 @@ limitations under the License.
 package scaleio
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
 	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
 )
 
 const (
-	name            = "scaleio"
-	version         = 2
-	statisticsPath  = "/api/instances/StoragePool::%s/relationships/Statistics"
-	storagePoolPath = "/api/types/StoragePool/instances"
-	NS_VENDOR       = "intel"
-	NS_PLUGIN       = "scaleio"
-	NS_SP           = "storagePool"
+	name                        = "scaleio"
+	version                     = 2
+	querySelectedStatisticsPath = "/api/instances/querySelectedStatistics"
+	instancesPath               = "/api/types/%s/instances"
+	queryMdmClusterPath         = "/api/instances/System/action/queryMdmCluster"
+	systemType                  = "System"
+	storagePoolType             = "StoragePool"
+	pdType                      = "ProtectionDomain"
+	sdsType                     = "Sds"
+	sdcType                     = "Sdc"
+	volumeType                  = "Volume"
+	deviceType                  = "Device"
+	faultSetType                = "FaultSet"
+	rfcacheDeviceType           = "RfcacheDevice"
+	vtreeType                   = "VTree"
+	NS_VENDOR                   = "intel"
+	NS_PLUGIN                   = "scaleio"
+	NS_SYSTEM                   = "system"
+	NS_SP                       = "storagePool"
+	NS_PD                       = "protectionDomain"
+	NS_SDS                      = "sds"
+	NS_SDC                      = "sdc"
+	NS_VOLUME                   = "volume"
+	NS_DEVICE                   = "device"
+	NS_FAULTSET                 = "faultSet"
+	NS_RFCACHE                  = "rfcacheDevice"
+	NS_VTREE                    = "vtree"
+	NS_MDM                      = "mdm"
 )
 
 // ScaleIO struct implements the collector interface and stores the target
 // system URL and credentials
 type ScaleIO struct {
+	// mutex guards the client cache and the samples below, as tasks are
+	// collected concurrently
+	mutex       sync.Mutex
 	clientCache map[string]*sioclient.SIOClient
+	// capacitySamples holds the used capacity samples of each storage pool
+	// within each forecast window
+	capacitySamples map[forecastKey][]capacitySample
+	// counterSamples holds the previous value of each counter for rate mode,
+	// by counterPattern and instance ID
+	counterSamples map[string]map[string]counterSample
 }
 
 //NewScaleIOCollector returns an instance of scaleIOCollector
 func NewScaleIOCollector() *ScaleIO {
 	clientCache := make(map[string]*sioclient.SIOClient)
 	return &ScaleIO{
-		clientCache: clientCache,
+		clientCache:     clientCache,
+		capacitySamples: make(map[forecastKey][]capacitySample),
+		counterSamples:  make(map[string]map[string]counterSample),
 	}
 }
 
@@ -58,20 +92,66 @@ func (s *ScaleIO) GetConfigPolicy() (plugin.ConfigPolicy, error) {
 	config.AddNewStringRule([]string{"intel", "scaleio"}, "username", true)
 	config.AddNewStringRule([]string{"intel", "scaleio"}, "password", true)
 	config.AddNewBoolRule([]string{"intel", "scaleio"}, "verifySSL", true, plugin.SetDefaultBool(true))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "forecastWindow", false, plugin.SetDefaultString(defaultForecastWindow))
+	config.AddNewBoolRule([]string{"intel", "scaleio"}, "rate", false, plugin.SetDefaultBool(false))
+	config.AddNewBoolRule([]string{"intel", "scaleio"}, "normalizeUnits", false, plugin.SetDefaultBool(false))
+	config.AddNewIntRule([]string{"intel", "scaleio"}, "retryMaxAttempts", false, plugin.SetDefaultInt(int64(sioclient.DefaultRetryPolicy.MaxAttempts)), plugin.SetMinInt(1))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "retryBaseDelay", false, plugin.SetDefaultString(sioclient.DefaultRetryPolicy.BaseDelay.String()))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "retryMaxDelay", false, plugin.SetDefaultString(sioclient.DefaultRetryPolicy.MaxDelay.String()))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "retryStatusCodes", false, plugin.SetDefaultString(defaultRetryStatusCodes))
+	config.AddNewBoolRule([]string{"intel", "scaleio"}, "retryConnectionErrors", false, plugin.SetDefaultBool(sioclient.DefaultRetryPolicy.RetryConnectionErrors))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "connectTimeout", false, plugin.SetDefaultString(sioclient.DefaultOptions.ConnectTimeout.String()))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "requestTimeout", false, plugin.SetDefaultString(sioclient.DefaultOptions.RequestTimeout.String()))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "collectTimeout", false, plugin.SetDefaultString("0s"))
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "caCertPath", false)
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "clientCertPath", false)
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "clientKeyPath", false)
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "serverName", false)
+	config.AddNewStringRule([]string{"intel", "scaleio"}, "proxy", false)
 
 	return *config, nil
 }
 
 // GetMetricTypes implements the collector interface requirements
-func (s *ScaleIO) GetMetricTypes(_ plugin.Config) ([]plugin.Metric, error) {
-	mts := make([]plugin.Metric, len(storagePoolMetricKeys))
+func (s *ScaleIO) GetMetricTypes(cfg plugin.Config) ([]plugin.Metric, error) {
+	mts := []plugin.Metric{}
+	normalize := normalizeUnits(cfg)
+	mts = append(mts, metricTypes(NS_SYSTEM, "systemID", "The specific system ID to collect from", catalogKeys(systemMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_SP, "storagePoolID", "The specific storage pool ID or name to collect from", catalogKeys(poolMetricKeys(), normalize))...)
+	mts = append(mts, metricTypes(NS_PD, "protectionDomainID", "The specific protection domain ID to collect from", catalogKeys(protectionDomainMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_SDS, "sdsID", "The specific SDS ID to collect from", catalogKeys(sdsMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_SDC, "sdcID", "The specific SDC ID to collect from", catalogKeys(sdcMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_VOLUME, "volumeID", "The specific volume ID to collect from", catalogKeys(volumeMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_DEVICE, "deviceID", "The specific device ID to collect from", catalogKeys(deviceMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_FAULTSET, "faultSetID", "The specific fault set ID to collect from", catalogKeys(faultSetMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_RFCACHE, "rfcacheDeviceID", "The specific RFcache device ID to collect from", catalogKeys(rfcacheDeviceMetricKeys, normalize))...)
+	mts = append(mts, metricTypes(NS_VTREE, "vtreeID", "The specific VTree ID to collect from", catalogKeys(vtreeMetricKeys, normalize))...)
+	for _, keys := range mdmMetricKeys {
+		namespace := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM).AddStaticElements(keys...)
+		mts = append(mts, plugin.Metric{Namespace: namespace})
+	}
+	namespace := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "member")
+	namespace = namespace.AddDynamicElement("memberID", "The specific MDM cluster member ID to collect from")
+	namespace = namespace.AddStaticElement("state")
+	mts = append(mts, plugin.Metric{Namespace: namespace})
+
+	mts = append(mts, s.poolMetricTypes(cfg)...)
+	return mts, nil
+}
+
+// metricTypes builds the metric types of a family exposed under
+// /intel/scaleio/<family>/<dynamic instance ID>/<keys...>, including the
+// derived ones
+func metricTypes(family string, idName string, idDescription string, keys [][]string) []plugin.Metric {
+	keys = withDerivedKeys(keys)
+	mts := make([]plugin.Metric, len(keys))
 	for i := 0; i < len(mts); i++ {
-		namespace := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP)
-		namespace = namespace.AddDynamicElement("storagePoolID", "The specific storage pool ID to collect from")
-		namespace = namespace.AddStaticElements(storagePoolMetricKeys[i]...)
+		namespace := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, family)
+		namespace = namespace.AddDynamicElement(idName, idDescription)
+		namespace = namespace.AddStaticElements(keys[i]...)
 		mts[i].Namespace = namespace
 	}
-	return mts, nil
+	return mts
 }
 
 // CollectMetrics implements the collector interface requirements
@@ -82,31 +162,71 @@ func (s *ScaleIO) CollectMetrics(mts []plugin.Metric) ([]plugin.Metric, error) {
 	if err != nil {
 		return nil, err
 	}
+	// bound the whole collection, all the requests are abandoned on timeout
+	ctx, cancel, err := collectContext(mts[0].Config)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
 	// ensure this is called frequently, we will cache the token and handle expiration
-	err = client.Authenticate()
+	err = client.Authenticate(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to authenticate SIO API Client")
 	}
+	gateway, _ := mts[0].Config.GetString("gateway")
+	forecastWindow, err := getForecastWindow(mts[0].Config)
+	if err != nil {
+		return nil, err
+	}
 
-	poolReqs := []plugin.Namespace{}
+	reqs := map[string][]plugin.Namespace{}
 
 	for _, m := range mts {
 		ns := m.Namespace
 		switch ns[2].Value {
-		case NS_SP:
-			poolReqs = append(poolReqs, ns)
+		case NS_SYSTEM, NS_SP, NS_PD, NS_SDS, NS_SDC, NS_VOLUME, NS_DEVICE, NS_FAULTSET, NS_RFCACHE, NS_VTREE, NS_MDM:
+			reqs[ns[2].Value] = append(reqs[ns[2].Value], ns)
 		default:
 			return nil, fmt.Errorf("Requested metric %s does not match any known scaleio metric", m.Namespace.String())
 		}
 	}
 
+	collectors := []struct {
+		family  string
+		collect func(context.Context, *sioclient.SIOClient, []plugin.Namespace) ([]plugin.Metric, error)
+	}{
+		{NS_SYSTEM, s.systemMetrics},
+		{NS_SP, func(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+			return s.poolMetrics(ctx, client, nss, gateway, forecastWindow)
+		}},
+		{NS_PD, s.protectionDomainMetrics},
+		{NS_SDS, s.sdsMetrics},
+		{NS_SDC, s.sdcMetrics},
+		{NS_VOLUME, s.volumeMetrics},
+		{NS_DEVICE, s.deviceMetrics},
+		{NS_FAULTSET, s.faultSetMetrics},
+		{NS_RFCACHE, s.rfcacheDeviceMetrics},
+		{NS_VTREE, s.vtreeMetrics},
+		{NS_MDM, s.mdmMetrics},
+	}
+
 	metrics := []plugin.Metric{}
+	for _, c := range collectors {
+		familyMts, err := c.collect(ctx, client, reqs[c.family])
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, familyMts...)
+	}
 
-	poolMts, err := s.poolMetrics(client, poolReqs)
-	if err != nil {
-		return nil, err
+	annotateUnits(metrics)
+	if rate, err := mts[0].Config.GetBool("rate"); err == nil && rate {
+		requested := make([]plugin.Namespace, len(mts))
+		for i, m := range mts {
+			requested[i] = m.Namespace
+		}
+		metrics = s.rates(gateway, requested, metrics)
 	}
-	metrics = append(metrics, poolMts...)
 
 	return metrics, nil
 }
@@ -130,13 +250,27 @@ func (s *ScaleIO) GetSIOClient(cfg plugin.Config) (*sioclient.SIOClient, error)
 	if err != nil {
 		return &sioclient.SIOClient{}, err
 	}
-	cachedClient, ok := s.clientCache[gateway]
+	retryPolicy, err := getRetryPolicy(cfg)
+	if err != nil {
+		return &sioclient.SIOClient{}, err
+	}
+	opts, err := getClientOptions(cfg)
+	if err != nil {
+		return &sioclient.SIOClient{}, err
+	}
+	// the settings are fixed once the client is created, as it is shared by
+	// the tasks using the same ones
+	key := fmt.Sprintf("%s %t %+v %+v", gateway, verifySSL, opts, retryPolicy)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	cachedClient, ok := s.clientCache[key]
 	if !ok {
-		newClient, err := sioclient.NewSIOClient(gateway, username, password, verifySSL)
+		newClient, err := sioclient.NewSIOClient(gateway, username, password, verifySSL, opts)
 		if err != nil {
 			return &sioclient.SIOClient{}, err
 		}
-		s.clientCache[gateway] = newClient
+		newClient.SetRetryPolicy(retryPolicy)
+		s.clientCache[key] = newClient
 		client = newClient
 	} else {
 		// TODO: add check for task config updated - new creds/etc
@@ -22,8 +22,15 @@ limitations under the License.
 package scaleio
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
 	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -61,7 +68,705 @@ func TestGetMetricTypes(t *testing.T) {
 			So(err, ShouldBeNil)
 		})
 		Convey("Has the correct number of metrics", func() {
-			So(metrics, ShouldHaveLength, len(storagePoolMetricKeys))
+			expected := 0
+			for _, keys := range [][][]string{
+				systemMetricKeys,
+				poolMetricKeys(),
+				protectionDomainMetricKeys,
+				sdsMetricKeys,
+				sdcMetricKeys,
+				volumeMetricKeys,
+				deviceMetricKeys,
+				faultSetMetricKeys,
+				rfcacheDeviceMetricKeys,
+				vtreeMetricKeys,
+				mdmMetricKeys,
+			} {
+				expected += len(withDerivedKeys(keys))
+			}
+			// the MDM member state
+			expected++
+			So(metrics, ShouldHaveLength, expected)
 		})
 	})
 }
+
+// newTestGateway starts a fake ScaleIO gateway answering each path with the
+// JSON encoding of the matching entry in responses
+func newTestGateway(responses map[string]interface{}) *httptest.Server {
+	return newCountingGateway(responses, map[string]int{})
+}
+
+// newCountingGateway returns a fake gateway like newTestGateway which counts
+// the API requests per path in counts
+func newCountingGateway(responses map[string]interface{}, counts map[string]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/login" {
+			fmt.Fprint(w, "\"token\"")
+			return
+		}
+		counts[r.URL.Path]++
+		resp, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+// newTestClient returns an authenticated client for the fake gateway
+func newTestClient(s *ScaleIO, gw *httptest.Server) *sioclient.SIOClient {
+	client, err := s.GetSIOClient(plugin.Config{
+		"gateway":   gw.URL,
+		"username":  "admin",
+		"password":  "password",
+		"verifySSL": true,
+	})
+	So(err, ShouldBeNil)
+	So(client.Authenticate(context.Background()), ShouldBeNil)
+	return client
+}
+
+func TestGetSIOClient(t *testing.T) {
+	Convey("GetSIOClient should share a client between identical configs only", t, func() {
+		s := NewScaleIOCollector()
+		cfg := func(attempts int64) plugin.Config {
+			return plugin.Config{
+				"gateway":          "https://gateway",
+				"username":         "admin",
+				"password":         "password",
+				"verifySSL":        true,
+				"retryMaxAttempts": attempts,
+			}
+		}
+		c1, err := s.GetSIOClient(cfg(3))
+		So(err, ShouldBeNil)
+		c2, err := s.GetSIOClient(cfg(3))
+		So(err, ShouldBeNil)
+		So(c2, ShouldEqual, c1)
+		c3, err := s.GetSIOClient(cfg(5))
+		So(err, ShouldBeNil)
+		So(c3, ShouldNotEqual, c1)
+		So(s.clientCache, ShouldHaveLength, 2)
+	})
+}
+
+func TestConcurrentCollectMetrics(t *testing.T) {
+	Convey("CollectMetrics should be safe for concurrent tasks sharing a client", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/types/ProtectionDomain/instances": []map[string]interface{}{},
+			"/api/types/StoragePool/instances": []map[string]interface{}{
+				{"id": "pool1"},
+			},
+			"/api/instances/querySelectedStatistics": map[string]interface{}{
+				"StoragePool": map[string]interface{}{
+					"pool1": map[string]interface{}{
+						"capacityInUseInKb":    100,
+						"maxCapacityInKb":      1000,
+						"rfcacheReadsReceived": 10,
+					},
+				},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		cfg := plugin.Config{
+			"gateway":   gw.URL,
+			"username":  "admin",
+			"password":  "password",
+			"verifySSL": true,
+			"rate":      true,
+		}
+		mts := []plugin.Metric{}
+		for _, keys := range [][]string{{"rfcacheReadsReceived"}, {forecastGroup, daysUntilFullKey}} {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+				AddDynamicElement("storagePoolID", "").
+				AddStaticElements(keys...)
+			mts = append(mts, plugin.Metric{Namespace: ns, Config: cfg})
+		}
+
+		errs := make(chan error)
+		for i := 0; i < 4; i++ {
+			go func() {
+				_, err := s.CollectMetrics(mts)
+				errs <- err
+			}()
+		}
+		for i := 0; i < 4; i++ {
+			So(<-errs, ShouldBeNil)
+		}
+		So(s.clientCache, ShouldHaveLength, 1)
+	})
+}
+
+func TestGetMetricTypesDiscovery(t *testing.T) {
+	Convey("GetMetricTypes should list the pools of a configured gateway", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/types/StoragePool/instances": []map[string]interface{}{
+				{"id": "pool1"},
+				{"id": "pool2"},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		static, err := s.GetMetricTypes(plugin.Config{})
+		So(err, ShouldBeNil)
+
+		metrics, err := s.GetMetricTypes(plugin.Config{
+			"gateway":  gw.URL,
+			"username": "admin",
+			"password": "password",
+		})
+		So(err, ShouldBeNil)
+		So(metrics, ShouldHaveLength, len(static)+2*len(withDerivedKeys(poolMetricKeys())))
+		concrete := metrics[len(static)].Namespace
+		So(concrete.String(), ShouldEqual, "/intel/scaleio/storagePool/pool1/"+storagePoolMetricKeys[0][0])
+
+		Convey("and keep the static types if the gateway cannot be reached", func() {
+			unreachable := httptest.NewServer(http.NotFoundHandler())
+			unreachable.Close()
+			metrics, err := s.GetMetricTypes(plugin.Config{
+				"gateway":               unreachable.URL,
+				"username":              "admin",
+				"password":              "password",
+				"retryConnectionErrors": false,
+			})
+			So(err, ShouldBeNil)
+			So(metrics, ShouldHaveLength, len(static))
+		})
+	})
+}
+
+func TestPoolMetrics(t *testing.T) {
+	Convey("poolMetrics should select pools by ID or by name and tag their topology", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/types/ProtectionDomain/instances": []map[string]interface{}{
+				{"id": "pd1", "systemId": "sys1"},
+			},
+			"/api/types/StoragePool/instances": []map[string]interface{}{
+				{"id": "96eb24f700000000", "name": "gold"},
+				{"id": "96eb24f800000001", "name": "silver", "protectionDomainId": "pd1",
+					"mediaType": "SSD", "zeroPaddingEnabled": true, "useRfcache": false},
+			},
+			"/api/instances/querySelectedStatistics": map[string]interface{}{
+				"StoragePool": map[string]interface{}{
+					"96eb24f700000000": map[string]interface{}{"numOfVolumes": 1},
+					"96eb24f800000001": map[string]interface{}{"numOfVolumes": 2},
+				},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+
+		for _, want := range []string{"96eb24f800000001", "silver", "name:silver"} {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+				AddDynamicElement("storagePoolID", "").
+				AddStaticElement("numOfVolumes")
+			ns[instanceIDIdx].Value = want
+			mts, err := s.poolMetrics(context.Background(), client, []plugin.Namespace{ns}, gw.URL, time.Hour)
+			So(err, ShouldBeNil)
+			So(mts, ShouldHaveLength, 1)
+			So(mts[0].Namespace[instanceIDIdx].Value, ShouldEqual, "96eb24f800000001")
+			So(mts[0].Data, ShouldEqual, float64(2))
+			So(mts[0].Tags["storagePoolName"], ShouldEqual, "silver")
+			So(mts[0].Tags["protectionDomainId"], ShouldEqual, "pd1")
+			So(mts[0].Tags["systemId"], ShouldEqual, "sys1")
+			So(mts[0].Tags["mediaType"], ShouldEqual, "SSD")
+			So(mts[0].Tags["zeroPaddingEnabled"], ShouldEqual, "true")
+			So(mts[0].Tags["useRfcache"], ShouldEqual, "false")
+			So(mts[0].Tags, ShouldNotContainKey, "useRmcache")
+			So(mts[0].Tags, ShouldNotContainKey, "rmcacheWriteHandlingMode")
+		}
+	})
+}
+
+func TestVolumeMetrics(t *testing.T) {
+	Convey("volumeMetrics should tag metrics with the volume topology", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/types/StoragePool/instances": []map[string]interface{}{
+				{"id": "pool1", "protectionDomainId": "pd1"},
+			},
+			"/api/types/Volume/instances": []map[string]interface{}{
+				{"id": "vol1", "name": "tenant-a", "storagePoolId": "pool1"},
+			},
+			"/api/instances/querySelectedStatistics": map[string]interface{}{
+				"Volume": map[string]interface{}{
+					"vol1": map[string]interface{}{"numOfMappedSdcs": 2},
+				},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+
+		ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_VOLUME).
+			AddDynamicElement("volumeID", "").
+			AddStaticElement("numOfMappedSdcs")
+		mts, err := s.volumeMetrics(context.Background(), client, []plugin.Namespace{ns})
+		So(err, ShouldBeNil)
+		So(mts, ShouldHaveLength, 1)
+		So(mts[0].Namespace[instanceIDIdx].Value, ShouldEqual, "vol1")
+		So(mts[0].Data, ShouldEqual, float64(2))
+		So(mts[0].Tags, ShouldResemble, map[string]string{
+			"volumeName":         "tenant-a",
+			"storagePoolId":      "pool1",
+			"protectionDomainId": "pd1",
+		})
+	})
+}
+
+func TestFaultSetMetrics(t *testing.T) {
+	Convey("faultSetMetrics should count the SDSs of each fault set", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/types/FaultSet/instances": []map[string]interface{}{
+				{"id": "fs1", "name": "rack1"},
+				{"id": "fs2", "name": "rack2"},
+			},
+			"/api/types/Sds/instances": []map[string]interface{}{
+				{"id": "sds1", "faultSetId": "fs1"},
+				{"id": "sds2", "faultSetId": "fs1"},
+				{"id": "sds3"},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+
+		ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_FAULTSET).
+			AddDynamicElement("faultSetID", "").
+			AddStaticElement(faultSetSdsKey)
+		mts, err := s.faultSetMetrics(context.Background(), client, []plugin.Namespace{ns})
+		So(err, ShouldBeNil)
+		So(mts, ShouldHaveLength, 2)
+		So(mts[0].Data, ShouldEqual, 2)
+		So(mts[0].Tags["faultSetName"], ShouldEqual, "rack1")
+		So(mts[1].Data, ShouldEqual, 0)
+	})
+}
+
+func TestSystemMetrics(t *testing.T) {
+	Convey("systemMetrics should query only the requested statistics in bulk", t, func() {
+		var query map[string][]selectedStatistics
+		gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/api/login":
+				fmt.Fprint(w, "\"token\"")
+			case "/api/types/System/instances":
+				fmt.Fprint(w, `[{"id": "sys1", "name": "cluster"}]`)
+			case "/api/instances/querySelectedStatistics":
+				json.NewDecoder(r.Body).Decode(&query)
+				fmt.Fprint(w, `{"System": {"numOfSds": 3, "totalReadBwc": {"numOccured": 7}}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+
+		nss := []plugin.Namespace{}
+		for _, keys := range [][]string{
+			{"numOfSds"},
+			{"totalReadBwc", "numOccured"},
+			{"totalReadBwc", "numSeconds"},
+		} {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SYSTEM).
+				AddDynamicElement("systemID", "").
+				AddStaticElements(keys...)
+			nss = append(nss, ns)
+		}
+		mts, err := s.systemMetrics(context.Background(), client, nss)
+		So(err, ShouldBeNil)
+		So(query["selectedStatisticsList"], ShouldResemble, []selectedStatistics{
+			{Type: "System", AllIDs: []string{}, Properties: []string{"numOfSds", "totalReadBwc"}},
+		})
+		So(mts, ShouldHaveLength, 3)
+		So(mts[0].Namespace[instanceIDIdx].Value, ShouldEqual, "sys1")
+		So(mts[0].Data, ShouldEqual, float64(3))
+		So(mts[1].Data, ShouldEqual, float64(7))
+		So(mts[2].Data, ShouldBeNil)
+
+		Convey("and skip instances other than the requested one", func() {
+			nss[0][instanceIDIdx].Value = "sys2"
+			mts, err := s.systemMetrics(context.Background(), client, nss[:1])
+			So(err, ShouldBeNil)
+			So(mts, ShouldBeEmpty)
+		})
+		Convey("and reject a namespace without a statistic key", func() {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SYSTEM).AddDynamicElement("systemID", "")
+			_, err := s.systemMetrics(context.Background(), client, []plugin.Namespace{ns})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestMdmMetrics(t *testing.T) {
+	Convey("mdmMetrics should report the MDM cluster health", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/instances/System/action/queryMdmCluster": map[string]interface{}{
+				"clusterMode":  "ThreeNodes",
+				"clusterState": "ClusteredDegraded",
+				"master":       map[string]interface{}{"id": "m1", "role": "Manager", "status": "Normal"},
+				"slaves": []map[string]interface{}{
+					{"id": "m2", "name": "mdm2", "role": "Manager", "status": "Disconnected"},
+				},
+				"tieBreakers": []map[string]interface{}{
+					{"id": "tb1", "role": "TieBreaker", "status": "Normal"},
+				},
+				"standbyMDMs": []map[string]interface{}{
+					{"id": "sb1", "role": "Manager"},
+				},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+
+		nss := []plugin.Namespace{
+			plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "clusterMode"),
+			plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "clusterState"),
+			plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "activeMembers"),
+			plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "standbyMembers"),
+			plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "member").
+				AddDynamicElement("memberID", "").
+				AddStaticElement("state"),
+		}
+		mts, err := s.mdmMetrics(context.Background(), client, nss)
+		So(err, ShouldBeNil)
+		So(mts, ShouldHaveLength, 8)
+		So(mts[0].Data, ShouldEqual, 3)
+		So(mts[1].Data, ShouldEqual, 1)
+		So(mts[2].Data, ShouldEqual, 3)
+		So(mts[3].Data, ShouldEqual, 1)
+		So(mts[5].Namespace[mdmMemberIdx].Value, ShouldEqual, "m2")
+		So(mts[5].Data, ShouldEqual, 2)
+		So(mts[5].Tags["memberType"], ShouldEqual, "slave")
+		So(mts[7].Tags, ShouldNotContainKey, "memberName")
+		So(mts[7].Data, ShouldEqual, unknownState)
+
+		Convey("A single member can be selected by ID or name", func() {
+			for _, member := range []string{"m2", "mdm2", "name:mdm2"} {
+				ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_MDM, "member", member, "state")
+				mts, err := s.mdmMetrics(context.Background(), client, []plugin.Namespace{ns})
+				So(err, ShouldBeNil)
+				So(mts, ShouldHaveLength, 1)
+				So(mts[0].Namespace[mdmMemberIdx].Value, ShouldEqual, "m2")
+				So(mts[0].Data, ShouldEqual, 2)
+			}
+		})
+	})
+}
+
+func TestBwcDerivations(t *testing.T) {
+	Convey("Bwc derivations should compute rates from the Bwc triple", t, func() {
+		b := toBwc(map[string]interface{}{
+			"numSeconds":      float64(5),
+			"totalWeightInKb": float64(800),
+			"numOccured":      float64(100),
+		})
+		for name, expected := range map[string]float64{"iops": 20, "kbps": 160, "avgIoSizeKb": 8} {
+			d, ok := findDerivation("primaryReadBwc", name)
+			So(ok, ShouldBeTrue)
+			So(d.compute(b), ShouldEqual, expected)
+		}
+		Convey("and guard against division by zero", func() {
+			for _, d := range bwcDerivations {
+				So(d.compute(bwc{}), ShouldEqual, 0)
+			}
+		})
+		Convey("and average latencies from the Latency triple", func() {
+			d, ok := findDerivation("userDataSdcReadLatency", "avgLatencyInMicrosec")
+			So(ok, ShouldBeTrue)
+			So(d.compute(b), ShouldEqual, 8)
+			So(d.compute(bwc{}), ShouldEqual, 0)
+		})
+		Convey("and only apply to Bwc and Latency structures", func() {
+			_, ok := findDerivation("numOfVolumes", "iops")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
+
+func TestPoolCapacityRatios(t *testing.T) {
+	Convey("The pool capacity group should compute capacity ratios", t, func() {
+		stats := map[string]interface{}{
+			"maxCapacityInKb":               float64(1000),
+			"capacityInUseInKb":             float64(400),
+			"unusedCapacityInKb":            float64(600),
+			"thinCapacityAllocatedInKm":     float64(1500),
+			"thinCapacityInUseInKb":         float64(300),
+			"thickCapacityInUseInKb":        float64(100),
+			"snapCapacityInUseOccupiedInKb": float64(40),
+		}
+		expected := map[string]float64{
+			"usedPercent":             40,
+			"freePercent":             60,
+			"thinProvisioningRatio":   5,
+			"overprovisioningRatio":   1.6,
+			"snapshotOverheadPercent": 10,
+		}
+		So(poolCapacityGroup.values, ShouldHaveLength, len(expected))
+		for name, value := range expected {
+			So(poolCapacityGroup.compute(name, stats), ShouldAlmostEqual, value)
+			So(poolCapacityGroup.compute(name, map[string]interface{}{}), ShouldEqual, 0)
+		}
+		Convey("and query only their inputs", func() {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+				AddDynamicElement("storagePoolID", "").
+				AddStaticElements("capacity", "usedPercent")
+			So(statisticsProperties(storagePoolType, []plugin.Namespace{ns}), ShouldResemble, poolCapacityGroup.inputs)
+		})
+	})
+}
+
+func TestDaysUntilFull(t *testing.T) {
+	Convey("daysUntilFull should forecast from the samples of the window", t, func() {
+		s := NewScaleIOCollector()
+		window := 48 * time.Hour
+		start := time.Now()
+		day := 24 * time.Hour
+
+		_, ok := s.daysUntilFull(forecastKey{"gw", "pool1", window}, capacitySample{start, 100}, 1000)
+		So(ok, ShouldBeFalse)
+
+		// growing 100KB per day with 800KB left
+		days, ok := s.daysUntilFull(forecastKey{"gw", "pool1", window}, capacitySample{start.Add(day), 200}, 1000)
+		So(ok, ShouldBeTrue)
+		So(days, ShouldAlmostEqual, 8)
+
+		Convey("and drop the samples which left the window", func() {
+			days, ok := s.daysUntilFull(forecastKey{"gw", "pool1", window}, capacitySample{start.Add(3 * day), 200}, 1000)
+			So(ok, ShouldBeTrue)
+			So(s.capacitySamples[forecastKey{"gw", "pool1", window}], ShouldHaveLength, 2)
+			So(days, ShouldEqual, neverFull)
+		})
+		Convey("and keep separate samples per window", func() {
+			_, ok := s.daysUntilFull(forecastKey{"gw", "pool1", time.Hour}, capacitySample{start.Add(day), 200}, 1000)
+			So(ok, ShouldBeFalse)
+			So(s.capacitySamples[forecastKey{"gw", "pool1", window}], ShouldHaveLength, 2)
+		})
+		Convey("and keep separate samples per gateway", func() {
+			_, ok := s.daysUntilFull(forecastKey{"gw2", "pool1", window}, capacitySample{start.Add(day), 200}, 1000)
+			So(ok, ShouldBeFalse)
+			So(s.capacitySamples[forecastKey{"gw", "pool1", window}], ShouldHaveLength, 2)
+		})
+		Convey("and forget the pools which are no longer sampled", func() {
+			s.daysUntilFull(forecastKey{"gw", "pool2", window}, capacitySample{start.Add(4 * day), 100}, 1000)
+			So(s.capacitySamples, ShouldNotContainKey, forecastKey{"gw", "pool1", window})
+		})
+	})
+}
+
+func TestGetForecastWindow(t *testing.T) {
+	Convey("getForecastWindow should only accept positive durations", t, func() {
+		d, err := getForecastWindow(plugin.Config{})
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 24*time.Hour)
+		d, err = getForecastWindow(plugin.Config{"forecastWindow": "2h"})
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 2*time.Hour)
+		for _, window := range []string{"0s", "-1h", "tomorrow"} {
+			_, err := getForecastWindow(plugin.Config{"forecastWindow": window})
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestPoolForecast(t *testing.T) {
+	Convey("poolMetrics should forecast from the single pool statistics query", t, func() {
+		counts := map[string]int{}
+		gw := newCountingGateway(map[string]interface{}{
+			"/api/types/ProtectionDomain/instances": []map[string]interface{}{},
+			"/api/types/StoragePool/instances": []map[string]interface{}{
+				{"id": "pool1", "name": "gold"},
+			},
+			"/api/instances/querySelectedStatistics": map[string]interface{}{
+				"StoragePool": map[string]interface{}{
+					"pool1": map[string]interface{}{"capacityInUseInKb": 100, "maxCapacityInKb": 1000},
+				},
+			},
+		}, counts)
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+
+		forecast := func(pool string) plugin.Namespace {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+				AddDynamicElement("storagePoolID", "").
+				AddStaticElements(forecastGroup, daysUntilFullKey)
+			ns[instanceIDIdx].Value = pool
+			return ns
+		}
+		capacity := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+			AddDynamicElement("storagePoolID", "").
+			AddStaticElement("capacityInUseInKb")
+		nss := []plugin.Namespace{forecast("*"), forecast("gold"), capacity}
+
+		mts, err := s.poolMetrics(context.Background(), client, nss, gw.URL, time.Hour)
+		So(err, ShouldBeNil)
+		So(mts, ShouldHaveLength, 1)
+		So(mts[0].Namespace.String(), ShouldEqual, "/intel/scaleio/storagePool/pool1/capacityInUseInKb")
+		So(counts["/api/instances/querySelectedStatistics"], ShouldEqual, 1)
+		So(counts["/api/types/StoragePool/instances"], ShouldEqual, 1)
+		So(s.capacitySamples[forecastKey{gw.URL, "pool1", time.Hour}], ShouldHaveLength, 1)
+
+		mts, err = s.poolMetrics(context.Background(), client, nss, gw.URL, time.Hour)
+		So(err, ShouldBeNil)
+		So(mts, ShouldHaveLength, 2)
+		So(mts[1].Namespace.String(), ShouldEqual, "/intel/scaleio/storagePool/pool1/forecast/daysUntilFull")
+		So(mts[1].Tags["storagePoolName"], ShouldEqual, "gold")
+		So(counts["/api/instances/querySelectedStatistics"], ShouldEqual, 2)
+		So(s.capacitySamples[forecastKey{gw.URL, "pool1", time.Hour}], ShouldHaveLength, 2)
+	})
+}
+
+func TestRates(t *testing.T) {
+	Convey("rates should convert counters to per-second rates", t, func() {
+		s := NewScaleIOCollector()
+		now := time.Now()
+		sample := func(key string, value float64, at time.Time) plugin.Metric {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+				AddDynamicElement("storagePoolID", "").
+				AddStaticElement(key)
+			ns[instanceIDIdx].Value = "pool1"
+			return plugin.Metric{Namespace: ns, Data: value, Timestamp: at}
+		}
+
+		mts := s.rates("gw", nil, []plugin.Metric{
+			sample("rfcacheReadsReceived", 100, now),
+			sample("numOfVolumes", 3, now),
+		})
+		So(mts, ShouldHaveLength, 1)
+		So(mts[0].Data, ShouldEqual, 3)
+
+		mts = s.rates("gw", nil, []plugin.Metric{sample("rfcacheReadsReceived", 150, now.Add(10*time.Second))})
+		So(mts, ShouldHaveLength, 1)
+		So(mts[0].Data, ShouldEqual, 5)
+
+		Convey("and drop counters which were reset", func() {
+			mts := s.rates("gw", nil, []plugin.Metric{sample("rfcacheReadsReceived", 20, now.Add(20*time.Second))})
+			So(mts, ShouldBeEmpty)
+			mts = s.rates("gw", nil, []plugin.Metric{sample("rfcacheReadsReceived", 40, now.Add(30*time.Second))})
+			So(mts[0].Data, ShouldEqual, 2)
+		})
+		Convey("with a per-second unit", func() {
+			So(mts[0].Unit, ShouldEqual, "1/s")
+			m := sample("BackgroundScannedInMB", 10, now)
+			m.Unit = "MB"
+			s.rates("gw", nil, []plugin.Metric{m})
+			m = sample("BackgroundScannedInMB", 30, now.Add(10*time.Second))
+			m.Unit = "MB"
+			mts := s.rates("gw", nil, []plugin.Metric{m})
+			So(mts[0].Unit, ShouldEqual, "MB/s")
+		})
+		Convey("and forget the instances missing from a wildcard request", func() {
+			wildcard := sample("rfcacheReadsReceived", 0, now).Namespace
+			wildcard[instanceIDIdx].Value = "*"
+			pool2 := sample("rfcacheReadsReceived", 10, now.Add(20*time.Second))
+			pool2.Namespace[instanceIDIdx].Value = "pool2"
+			s.rates("gw", []plugin.Namespace{wildcard}, []plugin.Metric{pool2})
+			pattern := counterPattern("gw", wildcard)
+			So(s.counterSamples[pattern], ShouldContainKey, "pool2")
+			So(s.counterSamples[pattern], ShouldNotContainKey, "pool1")
+		})
+		Convey("from concurrent collections", func() {
+			done := make(chan bool)
+			for i := 0; i < 4; i++ {
+				go func(i int) {
+					for j := 0; j < 100; j++ {
+						m := sample("rfcacheReadsReceived", float64(j), now.Add(time.Duration(j)*time.Second))
+						m.Namespace[instanceIDIdx].Value = fmt.Sprintf("pool%d", i)
+						s.rates("gw", nil, []plugin.Metric{m})
+					}
+					done <- true
+				}(i)
+			}
+			for i := 0; i < 4; i++ {
+				<-done
+			}
+			So(s.counterSamples[counterPattern("gw", sample("rfcacheReadsReceived", 0, now).Namespace)], ShouldHaveLength, 4)
+		})
+	})
+}
+
+func TestAnnotateUnits(t *testing.T) {
+	Convey("annotateUnits should set the unit of capacity metrics", t, func() {
+		metric := func(key string, value float64) plugin.Metric {
+			ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SP).
+				AddDynamicElement("storagePoolID", "").
+				AddStaticElement(key)
+			return plugin.Metric{Namespace: ns, Data: value}
+		}
+		mts := []plugin.Metric{
+			metric("capacityInUseInKb", 2),
+			metric("thinCapacityAllocatedInKm", 2),
+			metric("BackgroundScannedInMB", 2),
+			metric("numOfVolumes", 2),
+		}
+		annotateUnits(mts)
+		So(mts[0].Unit, ShouldEqual, "KB")
+		So(mts[0].Data, ShouldEqual, 2)
+		So(mts[1].Unit, ShouldEqual, "KB")
+		So(mts[2].Unit, ShouldEqual, "MB")
+		So(mts[3].Unit, ShouldEqual, "")
+
+		Convey("and convert the *InBytes metrics to bytes", func() {
+			mts := []plugin.Metric{
+				metric("capacityInUseInBytes", 2),
+				metric("thinCapacityAllocatedInBytes", 2),
+				metric("BackgroundScannedInBytes", 2),
+			}
+			annotateUnits(mts)
+			So(mts[0].Data, ShouldEqual, 2048)
+			So(mts[1].Data, ShouldEqual, 2048)
+			So(mts[2].Data, ShouldEqual, 2<<20)
+			for _, m := range mts {
+				So(m.Unit, ShouldEqual, bytesUnit)
+			}
+		})
+	})
+}
+
+func TestNormalizeUnits(t *testing.T) {
+	Convey("GetMetricTypes should advertise the capacity metrics in bytes when normalizing", t, func() {
+		s := NewScaleIOCollector()
+		raw, err := s.GetMetricTypes(plugin.Config{})
+		So(err, ShouldBeNil)
+		normalized, err := s.GetMetricTypes(plugin.Config{"normalizeUnits": true})
+		So(err, ShouldBeNil)
+		So(normalized, ShouldHaveLength, len(raw))
+		names := map[string]bool{}
+		for _, m := range normalized {
+			names[m.Namespace.String()] = true
+		}
+		So(names, ShouldContainKey, "/intel/scaleio/storagePool/*/capacityInUseInBytes")
+		So(names, ShouldContainKey, "/intel/scaleio/device/*/capacityInUseInBytes")
+		So(names, ShouldNotContainKey, "/intel/scaleio/storagePool/*/capacityInUseInKb")
+		So(names, ShouldContainKey, "/intel/scaleio/storagePool/*/primaryReadBwc/totalWeightInKb")
+	})
+	Convey("The *InBytes metrics should be read from their capacity statistic", t, func() {
+		gw := newTestGateway(map[string]interface{}{
+			"/api/types/Sds/instances": []map[string]interface{}{{"id": "sds1"}},
+			"/api/instances/querySelectedStatistics": map[string]interface{}{
+				"Sds": map[string]interface{}{
+					"sds1": map[string]interface{}{"capacityInUseInKb": 3},
+				},
+			},
+		})
+		defer gw.Close()
+		s := NewScaleIOCollector()
+		client := newTestClient(s, gw)
+		ns := plugin.NewNamespace(NS_VENDOR, NS_PLUGIN, NS_SDS).
+			AddDynamicElement("sdsID", "").
+			AddStaticElement("capacityInUseInBytes")
+		So(statisticsProperties(sdsType, []plugin.Namespace{ns}), ShouldResemble, []string{"capacityInUseInKb"})
+		mts, err := s.sdsMetrics(context.Background(), client, []plugin.Namespace{ns})
+		So(err, ShouldBeNil)
+		annotateUnits(mts)
+		So(mts, ShouldHaveLength, 1)
+		So(mts[0].Data, ShouldEqual, 3072)
+		So(mts[0].Unit, ShouldEqual, bytesUnit)
+	})
+}
@@ -0,0 +1,13 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// sdsMetrics collects the Statistics of every SDS (ScaleIO Data Server)
+func (s *ScaleIO) sdsMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	return statisticsMetrics(ctx, client, sdsType, nss, nil)
+}
@@ -0,0 +1,274 @@
+/*
+http://www.apache.org/licenses/LICENSE-2.0.txt
+
+
+Copyright 2016 Intel Corporation
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaleio
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+const (
+	// namePrefix selects an instance by name rather than by ID in the
+	// dynamic element of a namespace
+	namePrefix = "name:"
+	// instanceIDIdx is the position of the dynamic instance ID in every
+	// namespace, e.g. /intel/scaleio/storagePool/<ID>/...
+	instanceIDIdx = 3
+)
+
+// instanceTagger returns the tags to attach to every metric of an instance,
+// based on its entry in the instance listing
+type instanceTagger func(instance map[string]interface{}) map[string]string
+
+// selectedStatistics is a single entry of a querySelectedStatistics request
+type selectedStatistics struct {
+	Type       string   `json:"type"`
+	AllIDs     []string `json:"allIds"`
+	Properties []string `json:"properties"`
+}
+
+// statisticsMetrics lists every instance of the given ScaleIO object type and
+// extracts the requested namespaces from each instance's Statistics, which
+// are fetched for all instances at once. If tagger is not nil, the tags it
+// returns are added to the instance's metrics.
+func statisticsMetrics(ctx context.Context, client *sioclient.SIOClient, objType string, nss []plugin.Namespace, tagger instanceTagger) ([]plugin.Metric, error) {
+
+	results := []plugin.Metric{}
+	if len(nss) == 0 {
+		return results, nil
+	}
+
+	// Everything is dynamic right now so get the list of all the instances
+	instances, err := listInstances(ctx, client, objType)
+	if err != nil {
+		return nil, err
+	}
+	props := statisticsProperties(objType, nss)
+	stats, err := queryStatistics(ctx, client, objType, props)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, v := range instances {
+		id, ok := v["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("Found %s entry without an ID", objType)
+		}
+		var metrics map[string]interface{}
+		if objType == systemType {
+			// There is only one System so its statistics are not keyed by ID
+			metrics = stats
+		} else {
+			metrics, ok = stats[id].(map[string]interface{})
+			if !ok && len(props) > 0 {
+				// The instance was created after the statistics were
+				// queried, it will be collected on the next interval
+				continue
+			}
+		}
+		var tags map[string]string
+		if tagger != nil {
+			tags = withoutEmptyTags(tagger(v))
+		}
+		for _, ns := range nss {
+			// Skip instances other than the one asked for, if any
+			if !matchesInstance(ns[instanceIDIdx].Value, v) {
+				continue
+			}
+			// Slice out only the important part for now
+			dyn := make([]plugin.NamespaceElement, len(ns))
+			copy(dyn, ns)
+			dyn[instanceIDIdx].Value = id
+
+			currentNamespace := ns.Strings()[instanceIDIdx+1:]
+			if len(currentNamespace) == 0 {
+				return nil, fmt.Errorf("Invalid metric namespace given: %v", ns)
+			}
+			var data interface{}
+			group, isGroup := derivedGroups[objType][currentNamespace[0]]
+			if nonStatisticsKeys[objType][currentNamespace[0]] {
+				data = v[currentNamespace[0]]
+			} else if isGroup && len(currentNamespace) == 2 {
+				data = group.compute(currentNamespace[1], metrics)
+			} else if len(currentNamespace) == 1 {
+				source, _, _ := sourceKey(currentNamespace[0])
+				data = metrics[source]
+			} else if len(currentNamespace) == 2 {
+				subMap, ok := metrics[currentNamespace[0]].(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("Invalid data found for %s with on %s %s", ns, objType, id)
+				}
+				if d, ok := findDerivation(currentNamespace[0], currentNamespace[1]); ok {
+					data = d.compute(toBwc(subMap))
+				} else {
+					data = subMap[currentNamespace[1]]
+				}
+			} else {
+				return nil, fmt.Errorf("Invalid metric namespace given: %v", ns)
+			}
+
+			newMetric := plugin.Metric{
+				Namespace: dyn,
+				Timestamp: now,
+				Data:      data,
+				Tags:      tags,
+			}
+			results = append(results, newMetric)
+		}
+	}
+
+	return results, nil
+}
+
+// matchesInstance tells whether the dynamic element of a requested namespace
+// selects the given instance, either by ID, by name or by "name:<name>"
+func matchesInstance(want string, instance map[string]interface{}) bool {
+	if want == "*" {
+		return true
+	}
+	if strings.HasPrefix(want, namePrefix) {
+		return strings.TrimPrefix(want, namePrefix) == stringField(instance, "name")
+	}
+	return want == stringField(instance, "id") || want == stringField(instance, "name")
+}
+
+// statisticsProperties returns the Statistics properties needed to answer the
+// requested namespaces, without duplicates
+func statisticsProperties(objType string, nss []plugin.Namespace) []string {
+	props := []string{}
+	seen := map[string]bool{}
+	for _, ns := range nss {
+		if len(ns) <= instanceIDIdx+1 {
+			continue
+		}
+		prop, _, _ := sourceKey(ns[instanceIDIdx+1].Value)
+		if nonStatisticsKeys[objType][prop] {
+			continue
+		}
+		needed := []string{prop}
+		if group, ok := derivedGroups[objType][prop]; ok {
+			needed = group.inputs
+		}
+		for _, p := range needed {
+			if !seen[p] {
+				seen[p] = true
+				props = append(props, p)
+			}
+		}
+	}
+	return props
+}
+
+// queryStatistics fetches the given Statistics properties of every instance
+// of objType in a single request. The result is keyed by instance ID, except
+// for the System which returns its properties directly.
+func queryStatistics(ctx context.Context, client *sioclient.SIOClient, objType string, props []string) (map[string]interface{}, error) {
+	if len(props) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	query := map[string][]selectedStatistics{
+		"selectedStatisticsList": []selectedStatistics{
+			{Type: objType, AllIDs: []string{}, Properties: props},
+		},
+	}
+	var resp map[string]map[string]interface{}
+	err := client.PostAPIResponse(ctx, querySelectedStatisticsPath, query, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp[objType], nil
+}
+
+// listInstances returns the instance listing of the given ScaleIO object type
+func listInstances(ctx context.Context, client *sioclient.SIOClient, objType string) ([]map[string]interface{}, error) {
+	var instances []map[string]interface{}
+	err := client.GetAPIResponse(ctx, fmt.Sprintf(instancesPath, objType), &instances)
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// countMembers sets the data of every metric whose namespace ends with key to
+// the number of memberType instances that owner maps to the metric's instance
+// ID. The members are only listed if such a metric exists.
+func countMembers(ctx context.Context, client *sioclient.SIOClient, mts []plugin.Metric, key string, memberType string, owner func(member map[string]interface{}) string) error {
+	requested := false
+	for _, m := range mts {
+		if m.Namespace[len(m.Namespace)-1].Value == key {
+			requested = true
+			break
+		}
+	}
+	if !requested {
+		return nil
+	}
+	members, err := listInstances(ctx, client, memberType)
+	if err != nil {
+		return err
+	}
+	counts := map[string]int{}
+	for _, member := range members {
+		if id := owner(member); id != "" {
+			counts[id]++
+		}
+	}
+	for i := range mts {
+		ns := mts[i].Namespace
+		if ns[len(ns)-1].Value == key {
+			mts[i].Data = counts[ns[instanceIDIdx].Value]
+		}
+	}
+	return nil
+}
+
+// withoutEmptyTags removes the tags without a value, e.g. for fields missing
+// from an instance listing, as publishers such as InfluxDB reject them
+func withoutEmptyTags(tags map[string]string) map[string]string {
+	for k, v := range tags {
+		if v == "" {
+			delete(tags, k)
+		}
+	}
+	return tags
+}
+
+// stringField returns the string value stored under key in an instance
+// listing entry, or an empty string if there is none
+func stringField(instance map[string]interface{}, key string) string {
+	v, _ := instance[key].(string)
+	return v
+}
+
+// boolField returns the boolean value stored under key in an instance listing
+// entry as a string, or an empty string if there is none
+func boolField(instance map[string]interface{}, key string) string {
+	v, ok := instance[key].(bool)
+	if !ok {
+		return ""
+	}
+	return strconv.FormatBool(v)
+}
@@ -0,0 +1,18 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// systemMetrics collects the cluster-wide Statistics of the System
+func (s *ScaleIO) systemMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	tagger := func(system map[string]interface{}) map[string]string {
+		return map[string]string{
+			"systemName": stringField(system, "name"),
+		}
+	}
+	return statisticsMetrics(ctx, client, systemType, nss, tagger)
+}
@@ -0,0 +1,103 @@
+package scaleio
+
+import (
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+const (
+	bytesUnit = "B"
+	// bytesSuffix replaces the unit suffix of a capacity key in the name of
+	// its conversion to bytes, e.g. capacityInUseInBytes
+	bytesSuffix = "InBytes"
+)
+
+// capacityUnit is the unit of a capacity statistic and its size in bytes
+type capacityUnit struct {
+	unit  string
+	bytes float64
+}
+
+var (
+	kbUnit = capacityUnit{"KB", 1 << 10}
+	mbUnit = capacityUnit{"MB", 1 << 20}
+)
+
+// sourceKeys maps the *InBytes keys to the capacity statistic they are
+// converted from
+var sourceKeys = func() map[string]string {
+	keys := make(map[string]string, len(metricUnits))
+	for key := range metricUnits {
+		keys[bytesKey(key)] = key
+	}
+	return keys
+}()
+
+// unitOf returns the unit of a capacity statistic
+func unitOf(key string) (capacityUnit, bool) {
+	u, ok := metricUnits[key]
+	return u, ok
+}
+
+// bytesKey returns the key of the conversion to bytes of a capacity statistic
+func bytesKey(key string) string {
+	// All the unit suffixes (InKb, InKm, InMB) have the same length
+	return key[:len(key)-len("InKb")] + bytesSuffix
+}
+
+// sourceKey returns the statistic key that a metric key is read from, along
+// with the unit to convert it from if the metric key is its conversion to
+// bytes
+func sourceKey(key string) (string, capacityUnit, bool) {
+	source, ok := sourceKeys[key]
+	if !ok {
+		return key, capacityUnit{}, false
+	}
+	return source, metricUnits[source], true
+}
+
+// normalizeUnits tells whether the config asks for the capacity statistics
+// in bytes
+func normalizeUnits(cfg plugin.Config) bool {
+	normalize, err := cfg.GetBool("normalizeUnits")
+	return err == nil && normalize
+}
+
+// catalogKeys returns the metric keys to advertise. If normalize is set the
+// capacity statistics are replaced with their conversion to bytes.
+func catalogKeys(keys [][]string, normalize bool) [][]string {
+	if !normalize {
+		return keys
+	}
+	results := make([][]string, len(keys))
+	for i, key := range keys {
+		if _, ok := unitOf(key[0]); ok && len(key) == 1 {
+			key = []string{bytesKey(key[0])}
+		}
+		results[i] = key
+	}
+	return results
+}
+
+// annotateUnits sets the unit of the capacity metrics among mts, and converts
+// to bytes the value of the ones requested under their *InBytes key
+func annotateUnits(mts []plugin.Metric) {
+	for i := range mts {
+		ns := mts[i].Namespace
+		if len(ns) != instanceIDIdx+2 {
+			continue
+		}
+		key := ns[instanceIDIdx+1].Value
+		if u, ok := unitOf(key); ok {
+			mts[i].Unit = u.unit
+			continue
+		}
+		_, u, ok := sourceKey(key)
+		if !ok {
+			continue
+		}
+		if value, ok := mts[i].Data.(float64); ok {
+			mts[i].Data = value * u.bytes
+			mts[i].Unit = bytesUnit
+		}
+	}
+}
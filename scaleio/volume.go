@@ -0,0 +1,35 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+// volumeMetrics collects the Statistics of every Volume, tagged with the
+// volume name, its storage pool and its protection domain
+func (s *ScaleIO) volumeMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	if len(nss) == 0 {
+		return []plugin.Metric{}, nil
+	}
+	// Volumes only reference their storage pool, so map the pools to their
+	// protection domains
+	pools, err := listInstances(ctx, client, storagePoolType)
+	if err != nil {
+		return nil, err
+	}
+	poolDomains := make(map[string]string, len(pools))
+	for _, p := range pools {
+		poolDomains[stringField(p, "id")] = stringField(p, "protectionDomainId")
+	}
+	tagger := func(volume map[string]interface{}) map[string]string {
+		poolID := stringField(volume, "storagePoolId")
+		return map[string]string{
+			"volumeName":         stringField(volume, "name"),
+			"storagePoolId":      poolID,
+			"protectionDomainId": poolDomains[poolID],
+		}
+	}
+	return statisticsMetrics(ctx, client, volumeType, nss, tagger)
+}
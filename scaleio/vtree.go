@@ -0,0 +1,41 @@
+package scaleio
+
+import (
+	"context"
+
+	sioclient "github.com/intelsdi-x/snap-plugin-collector-scaleio/scaleio/client"
+	"github.com/intelsdi-x/snap-plugin-lib-go/v1/plugin"
+)
+
+const (
+	// vtreeSnapshotsKey is the derived gauge holding the number of snapshots
+	// in a VTree
+	vtreeSnapshotsKey = "numOfSnapshots"
+)
+
+// vtreeMetrics collects the Statistics of every VTree along with the number
+// of snapshots in each of them, tagged with the base volume and storage pool
+func (s *ScaleIO) vtreeMetrics(ctx context.Context, client *sioclient.SIOClient, nss []plugin.Namespace) ([]plugin.Metric, error) {
+	tagger := func(vtree map[string]interface{}) map[string]string {
+		return map[string]string{
+			"vtreeName":     stringField(vtree, "name"),
+			"baseVolumeId":  stringField(vtree, "baseVolumeId"),
+			"storagePoolId": stringField(vtree, "storagePoolId"),
+		}
+	}
+	mts, err := statisticsMetrics(ctx, client, vtreeType, nss, tagger)
+	if err != nil {
+		return nil, err
+	}
+
+	err = countMembers(ctx, client, mts, vtreeSnapshotsKey, volumeType, func(volume map[string]interface{}) string {
+		if stringField(volume, "volumeType") != "Snapshot" {
+			return ""
+		}
+		return stringField(volume, "vtreeId")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mts, nil
+}